@@ -0,0 +1,124 @@
+package zrr
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Context error codes.
+const (
+	// ECCanceled represents canceled context error code.
+	ECCanceled = "ECCanceled"
+
+	// ECDeadline represents exceeded context deadline error code.
+	ECDeadline = "ECDeadline"
+)
+
+// ErrCanceled represents package level error used as the cancellation cause
+// when none was provided.
+var ErrCanceled = Imm("context canceled", ECCanceled)
+
+// Context error metadata keys.
+const (
+	// KeyCause is the metadata key for the context cancellation cause message.
+	KeyCause = "cause"
+
+	// KeyDeadline is the metadata key for the context deadline.
+	KeyDeadline = "deadline"
+
+	// KeyElapsed is the metadata key for the time elapsed since the context
+	// was created with one of WithCancel, WithTimeout or WithDeadline.
+	KeyElapsed = "elapsed"
+)
+
+// CancelFunc cancels the context with the error as the cause.
+// When cause is nil ErrCanceled is used.
+type CancelFunc func(cause error)
+
+// startKey is the context key for the context creation time.
+type startKey struct{}
+
+// WithCancel returns a copy of parent which can be canceled with an error as
+// the cause. The cause is available via context.Cause and WrapCtxErr.
+func WithCancel(parent context.Context) (context.Context, CancelFunc) {
+	ctx, cancel := context.WithCancelCause(withStart(parent))
+	return ctx, func(cause error) {
+		if cause == nil {
+			cause = ErrCanceled
+		}
+		cancel(cause)
+	}
+}
+
+// WithTimeout returns a copy of parent which is canceled with cause after
+// timeout d. When cause is nil context.DeadlineExceeded is used.
+func WithTimeout(parent context.Context, d time.Duration, cause error) (context.Context, context.CancelFunc) {
+	return context.WithTimeoutCause(withStart(parent), d, cause)
+}
+
+// WithDeadline returns a copy of parent which is canceled with cause when
+// the deadline passes. When cause is nil context.DeadlineExceeded is used.
+func WithDeadline(parent context.Context, d time.Time, cause error) (context.Context, context.CancelFunc) {
+	return context.WithDeadlineCause(withStart(parent), d, cause)
+}
+
+// withStart returns a copy of ctx carrying the current time as the context
+// creation time.
+func withStart(ctx context.Context) context.Context {
+	return context.WithValue(ctx, startKey{}, time.Now())
+}
+
+// WrapCtxErr wraps err in Error instance the same way WrapCtx does. When err
+// is the ctx error (context.Canceled or context.DeadlineExceeded) it sets
+// ECCanceled or ECDeadline code respectively and adds metadata:
+//
+//   - KeyCause - the message of the cancellation cause, when it's different
+//     from ctx error,
+//   - KeyDeadline - the context deadline, when set,
+//   - KeyElapsed - the time elapsed since the context was created, when the
+//     context was created with WithCancel, WithTimeout or WithDeadline.
+//
+// The cancellation cause is part of the returned error chain, so errors.Is
+// and errors.As work with it. It returns nil if err is nil or typed nil
+// (nil pointer).
+func WrapCtxErr(ctx context.Context, err error) *Error {
+	if isNil(err) {
+		return nil
+	}
+	cerr := ctx.Err()
+	if cerr == nil || !errors.Is(err, cerr) {
+		return WrapCtx(ctx, err)
+	}
+
+	code := ECCanceled
+	if errors.Is(cerr, context.DeadlineExceeded) {
+		code = ECDeadline
+	}
+
+	cause := context.Cause(ctx)
+	if cause != nil && cause != cerr {
+		err = &causeErr{error: err, cause: cause}
+	}
+
+	e := base(err, false, code)
+	if cause != nil && cause != cerr {
+		e.set(KeyCause, cause.Error())
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		e.set(KeyDeadline, deadline)
+	}
+	if start, ok := ctx.Value(startKey{}).(time.Time); ok {
+		e.set(KeyElapsed, time.Since(start))
+	}
+	return e.FromCtx(ctx)
+}
+
+// causeErr represents context error with its cancellation cause.
+type causeErr struct {
+	error       // Context error.
+	cause error // Cancellation cause.
+}
+
+// Unwrap returns the context error and the cancellation cause.
+func (e *causeErr) Unwrap() []error { return []error{e.error, e.cause} }
@@ -0,0 +1,186 @@
+package zrr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ctx42/testing/pkg/assert"
+)
+
+func Test_WithCancel(t *testing.T) {
+	t.Run("with cause", func(t *testing.T) {
+		// --- Given ---
+		cause := New("shutting down", "ECShutdown")
+		ctx, cancel := WithCancel(context.Background())
+
+		// --- When ---
+		cancel(cause)
+
+		// --- Then ---
+		assert.ErrorIs(t, context.Canceled, ctx.Err())
+		assert.Same(t, cause, context.Cause(ctx))
+		_, ok := ctx.Value(startKey{}).(time.Time)
+		assert.True(t, ok)
+	})
+
+	t.Run("nil cause", func(t *testing.T) {
+		// --- Given ---
+		ctx, cancel := WithCancel(context.Background())
+
+		// --- When ---
+		cancel(nil)
+
+		// --- Then ---
+		assert.Same(t, ErrCanceled, context.Cause(ctx))
+	})
+}
+
+func Test_WithTimeout(t *testing.T) {
+	// --- Given ---
+	cause := New("too slow", "ECSlow")
+
+	// --- When ---
+	ctx, cancel := WithTimeout(context.Background(), time.Millisecond, cause)
+	defer cancel()
+
+	// --- Then ---
+	<-ctx.Done()
+	assert.ErrorIs(t, context.DeadlineExceeded, ctx.Err())
+	assert.Same(t, cause, context.Cause(ctx))
+}
+
+func Test_WithDeadline(t *testing.T) {
+	// --- Given ---
+	deadline := time.Now().Add(time.Millisecond)
+
+	// --- When ---
+	ctx, cancel := WithDeadline(context.Background(), deadline, nil)
+	defer cancel()
+
+	// --- Then ---
+	<-ctx.Done()
+	assert.ErrorIs(t, context.DeadlineExceeded, context.Cause(ctx))
+	got, _ := ctx.Deadline()
+	assert.Equal(t, deadline, got)
+}
+
+func Test_WrapCtxErr(t *testing.T) {
+	t.Run("canceled with cause", func(t *testing.T) {
+		// --- Given ---
+		cause := New("shutting down", "ECShutdown")
+		ctx, cancel := WithCancel(context.Background())
+		cancel(cause)
+
+		// --- When ---
+		err := WrapCtxErr(ctx, ctx.Err())
+
+		// --- Then ---
+		assert.Equal(t, "context canceled", err.Error())
+		assert.Equal(t, ECCanceled, err.ErrCode())
+		assert.ErrorIs(t, context.Canceled, err)
+		assert.ErrorIs(t, cause, err)
+		msg, _ := GetStr(err, KeyCause)
+		assert.Equal(t, "shutting down", msg)
+		_, ok := GetDuration(err, KeyElapsed)
+		assert.True(t, ok)
+		assert.False(t, HasKey(err, KeyDeadline))
+	})
+
+	t.Run("canceled without cause", func(t *testing.T) {
+		// --- Given ---
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		// --- When ---
+		err := WrapCtxErr(ctx, ctx.Err())
+
+		// --- Then ---
+		assert.Equal(t, ECCanceled, err.ErrCode())
+		assert.Same(t, context.Canceled, err.Unwrap())
+		assert.Len(t, 0, err.GetMetadata())
+	})
+
+	t.Run("deadline exceeded", func(t *testing.T) {
+		// --- Given ---
+		cause := New("too slow", "ECSlow")
+		deadline := time.Now().Add(time.Millisecond)
+		ctx, cancel := WithDeadline(context.Background(), deadline, cause)
+		defer cancel()
+		<-ctx.Done()
+
+		// --- When ---
+		err := WrapCtxErr(ctx, fmt.Errorf("query: %w", ctx.Err()))
+
+		// --- Then ---
+		assert.Equal(t, "query: context deadline exceeded", err.Error())
+		assert.Equal(t, ECDeadline, err.ErrCode())
+		assert.ErrorIs(t, context.DeadlineExceeded, err)
+		assert.ErrorIs(t, cause, err)
+		msg, _ := GetStr(err, KeyCause)
+		assert.Equal(t, "too slow", msg)
+		got, _ := GetTime(err, KeyDeadline)
+		assert.Equal(t, deadline, got)
+		elapsed, _ := GetDuration(err, KeyElapsed)
+		assert.True(t, elapsed >= time.Millisecond)
+	})
+
+	t.Run("context metadata", func(t *testing.T) {
+		// --- Given ---
+		ctx, cancel := context.WithCancel(WithMeta(context.Background(), "rid", "r1"))
+		cancel()
+
+		// --- When ---
+		err := WrapCtxErr(ctx, ctx.Err())
+
+		// --- Then ---
+		assert.Equal(t, map[string]any{"rid": "r1"}, err.GetMetadata())
+	})
+
+	t.Run("not context error", func(t *testing.T) {
+		// --- Given ---
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		e := errors.New("std error")
+
+		// --- When ---
+		err := WrapCtxErr(ctx, e)
+
+		// --- Then ---
+		assert.Same(t, e, err.Unwrap())
+		assert.Equal(t, "", err.ErrCode())
+	})
+
+	t.Run("context not done", func(t *testing.T) {
+		// --- Given ---
+		e := New("em0", "ECode")
+
+		// --- When ---
+		err := WrapCtxErr(context.Background(), e)
+
+		// --- Then ---
+		assert.Same(t, e, err)
+		assert.Equal(t, "ECode", err.ErrCode())
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		// --- When ---
+		err := WrapCtxErr(context.Background(), nil)
+
+		// --- Then ---
+		assert.Nil(t, err)
+	})
+
+	t.Run("typed nil", func(t *testing.T) {
+		// --- Given ---
+		var ne *nilErr
+
+		// --- When ---
+		err := WrapCtxErr(context.Background(), ne)
+
+		// --- Then ---
+		assert.Nil(t, err)
+	})
+}
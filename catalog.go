@@ -0,0 +1,218 @@
+package zrr
+
+import (
+	"encoding/json"
+	"io/fs"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Catalog represents localized error messages keyed by language and code.
+// Messages may have "{key}" placeholders rendered from the public metadata
+// (see Public). It's safe for concurrent use.
+type Catalog struct {
+	mx       sync.RWMutex
+	msgs     map[string]map[string]string // Messages by language and code.
+	fallback []string                     // Fallback languages.
+}
+
+// NewCatalog returns new empty Catalog. The fallback languages are tried in
+// order when message is not found in any of the requested languages.
+func NewCatalog(fallback ...string) *Catalog {
+	c := &Catalog{msgs: make(map[string]map[string]string)}
+	for _, lang := range fallback {
+		c.fallback = append(c.fallback, normLang(lang))
+	}
+	return c
+}
+
+// Add adds localized message for the code.
+func (c *Catalog) Add(lang, code, msg string) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	c.add(normLang(lang), code, msg)
+}
+
+// add adds localized message for the code. The lang must be normalized.
+func (c *Catalog) add(lang, code, msg string) {
+	msgs, ok := c.msgs[lang]
+	if !ok {
+		msgs = make(map[string]string)
+		c.msgs[lang] = msgs
+	}
+	msgs[code] = msg
+}
+
+// Load loads messages from JSON files in the dir directory of fsys. Each
+// file holds messages of one language, the file name without the ".json"
+// extension is the language, and the file content is a JSON object mapping
+// codes to messages. Use os.DirFS to load files from disk or embed.FS to
+// load embedded files.
+func (c *Catalog) Load(fsys fs.FS, dir string) error {
+	names, err := fs.Glob(fsys, path.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	langs := make(map[string]map[string]string, len(names))
+	for _, name := range names {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		var msgs map[string]string
+		if err = json.Unmarshal(data, &msgs); err != nil {
+			return Wrap(err, ECInvJSON).Str("file", name)
+		}
+		langs[normLang(strings.TrimSuffix(path.Base(name), ".json"))] = msgs
+	}
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	for lang, msgs := range langs {
+		for code, msg := range msgs {
+			c.add(lang, code, msg)
+		}
+	}
+	return nil
+}
+
+// Message returns message for the code in the first language which has it.
+// Languages are tried in order, each followed by its base language (e.g.
+// "pt" for "pt-BR"), then the catalog fallback languages are tried.
+func (c *Catalog) Message(code string, langs ...string) (string, bool) {
+	return c.find([]string{code}, append(chain(langs), c.fallback...))
+}
+
+// find returns message for the first code which has a message in any of
+// the normalized languages. For each code the languages are tried in order.
+func (c *Catalog) find(codes, langs []string) (string, bool) {
+	c.mx.RLock()
+	defer c.mx.RUnlock()
+	for _, code := range codes {
+		for _, lang := range langs {
+			if msg, ok := c.msgs[lang][code]; ok {
+				return msg, true
+			}
+		}
+	}
+	return "", false
+}
+
+// chain returns normalized languages to try in order, each followed by its
+// base language.
+func chain(langs []string) []string {
+	var chain []string
+	add := func(lang string) {
+		if lang != "" && !slices.Contains(chain, lang) {
+			chain = append(chain, lang)
+		}
+	}
+	for _, lang := range langs {
+		lang = normLang(lang)
+		add(lang)
+		if i := strings.IndexByte(lang, '-'); i > 0 {
+			add(lang[:i])
+		}
+	}
+	return chain
+}
+
+// Localize returns localized message of err. The codes in the err chain are
+// tried in order (see Codes) in the requested languages and their base
+// languages first. Only when none of the codes has a message in them, the
+// codes are tried in the catalog fallback languages. When no message is
+// found the public message is returned (see Public).
+func (c *Catalog) Localize(err error, langs ...string) string {
+	view := Public(err)
+	codes := Codes(err)
+	msg, ok := c.find(codes, chain(langs))
+	if !ok {
+		msg, ok = c.find(codes, c.fallback)
+	}
+	if ok {
+		return renderMsg(msg, view.Fields())
+	}
+	return view.Message
+}
+
+// LocalizeAccept returns localized message of err for languages listed in
+// the Accept-Language HTTP header value.
+func (c *Catalog) LocalizeAccept(err error, header string) string {
+	return c.Localize(err, ParseAcceptLanguage(header)...)
+}
+
+// catalog is the package wide message catalog.
+var catalog atomic.Pointer[Catalog]
+
+func init() { catalog.Store(NewCatalog()) }
+
+// SetCatalog sets the package wide message catalog used by Localize and
+// LocalizeAccept functions.
+func SetCatalog(c *Catalog) { catalog.Store(c) }
+
+// GetCatalog returns the package wide message catalog.
+func GetCatalog() *Catalog { return catalog.Load() }
+
+// Localize returns localized message of err using the package wide message
+// catalog. See Catalog.Localize for details.
+func Localize(err error, langs ...string) string {
+	return GetCatalog().Localize(err, langs...)
+}
+
+// LocalizeAccept returns localized message of err for languages listed in
+// the Accept-Language HTTP header value using the package wide message
+// catalog.
+func LocalizeAccept(err error, header string) string {
+	return GetCatalog().LocalizeAccept(err, header)
+}
+
+// ParseAcceptLanguage returns languages listed in the Accept-Language HTTP
+// header value ordered by their quality values. Languages with zero quality
+// and the "*" wildcard are skipped.
+func ParseAcceptLanguage(header string) []string {
+	type entry struct {
+		lang string
+		q    float64
+	}
+	var entries []entry
+	for _, part := range strings.Split(header, ",") {
+		lang, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		lang = strings.TrimSpace(lang)
+		if lang == "" || lang == "*" {
+			continue
+		}
+		q := 1.0
+		if val, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			var err error
+			if q, err = strconv.ParseFloat(val, 64); err != nil {
+				continue
+			}
+		}
+		if q > 0 {
+			entries = append(entries, entry{lang, q})
+		}
+	}
+	slices.SortStableFunc(entries, func(a, b entry) int {
+		switch {
+		case a.q > b.q:
+			return -1
+		case a.q < b.q:
+			return 1
+		}
+		return 0
+	})
+	langs := make([]string, 0, len(entries))
+	for _, e := range entries {
+		langs = append(langs, e.lang)
+	}
+	return langs
+}
+
+// normLang returns normalized language tag.
+func normLang(lang string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(lang), "_", "-"))
+}
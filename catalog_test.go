@@ -0,0 +1,215 @@
+package zrr
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"testing/fstest"
+
+	"github.com/ctx42/testing/pkg/assert"
+)
+
+// setCatalog sets the package wide catalog for the duration of the test.
+func setCatalog(t *testing.T, c *Catalog) {
+	t.Helper()
+	saved := GetCatalog()
+	t.Cleanup(func() { SetCatalog(saved) })
+	SetCatalog(c)
+}
+
+func Test_Catalog_Message(t *testing.T) {
+	// --- Given ---
+	c := NewCatalog("en")
+	c.Add("en", "ECode", "en msg")
+	c.Add("pt", "ECode", "pt msg")
+	c.Add("pt_BR", "ECode", "pt-BR msg")
+	c.Add("pl", "ECOther", "pl other")
+
+	tt := []struct {
+		testN string
+
+		exp   string
+		found bool
+		code  string
+		langs []string
+	}{
+		{"exact", "pt-BR msg", true, "ECode", []string{"pt-BR"}},
+		{"case insensitive", "pt-BR msg", true, "ECode", []string{"PT-br"}},
+		{"base language", "pt msg", true, "ECode", []string{"pt-PT"}},
+		{"first language wins", "pt msg", true, "ECode", []string{"pt", "en"}},
+		{"next language", "pt msg", true, "ECode", []string{"de", "pt"}},
+		{"fallback", "en msg", true, "ECode", []string{"pl"}},
+		{"no languages", "en msg", true, "ECode", nil},
+		{"not found", "", false, "ECUnknown", []string{"pl"}},
+		{"only in requested", "pl other", true, "ECOther", []string{"pl"}},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			have, found := c.Message(tc.code, tc.langs...)
+			assert.Equal(t, tc.found, found)
+			assert.Equal(t, tc.exp, have)
+		})
+	}
+}
+
+func Test_Catalog_Load(t *testing.T) {
+	t.Run("files", func(t *testing.T) {
+		// --- Given ---
+		fsys := fstest.MapFS{
+			"msgs/en.json":    {Data: []byte(`{"ECode": "en msg"}`)},
+			"msgs/pt_BR.json": {Data: []byte(`{"ECode": "pt-BR msg"}`)},
+			"msgs/readme.txt": {Data: []byte(`not a catalog`)},
+		}
+		c := NewCatalog()
+
+		// --- When ---
+		err := c.Load(fsys, "msgs")
+
+		// --- Then ---
+		assert.NoError(t, err)
+		have, _ := c.Message("ECode", "en")
+		assert.Equal(t, "en msg", have)
+		have, _ = c.Message("ECode", "pt-BR")
+		assert.Equal(t, "pt-BR msg", have)
+		assert.Len(t, 2, c.msgs)
+	})
+
+	t.Run("invalid JSON", func(t *testing.T) {
+		// --- Given ---
+		fsys := fstest.MapFS{
+			"en.json": {Data: []byte(`{"ECode": "en msg"}`)},
+			"pl.json": {Data: []byte(`{`)},
+		}
+		c := NewCatalog()
+
+		// --- When ---
+		err := c.Load(fsys, ".")
+
+		// --- Then ---
+		assert.True(t, HasCode(err, ECInvJSON))
+		name, _ := GetStr(err, "file")
+		assert.Equal(t, "pl.json", name)
+		assert.Len(t, 0, c.msgs)
+	})
+}
+
+func Test_Catalog_Localize(t *testing.T) {
+	// --- Given ---
+	c := NewCatalog("en")
+	c.Add("en", "ECUserNotFound", "user {user_id} not found")
+	c.Add("pl", "ECUserNotFound", "nie znaleziono użytkownika {user_id}")
+	c.Add("pl", "ECDB", "błąd bazy danych")
+
+	t.Run("localized", func(t *testing.T) {
+		// --- Given ---
+		err := New("pq: no rows", "ECUserNotFound").
+			Int("user_id", 42).
+			Str("query", "SELECT").
+			SetPublic("user not found", "user_id")
+
+		// --- Then ---
+		assert.Equal(t, "nie znaleziono użytkownika 42", c.Localize(err, "pl-PL"))
+		assert.Equal(t, "user 42 not found", c.Localize(err, "de"))
+	})
+
+	t.Run("only public keys are rendered", func(t *testing.T) {
+		// --- Given ---
+		err := New("pq: no rows", "ECUserNotFound").Int("user_id", 42)
+
+		// --- Then ---
+		assert.Equal(t, "user {user_id} not found", c.Localize(err, "en"))
+	})
+
+	t.Run("code deeper in chain", func(t *testing.T) {
+		// --- Given ---
+		e0 := New("pq: no rows", "ECDB")
+		err := Wrap(fmt.Errorf("w: %w", e0), "ECUnknown")
+
+		// --- Then ---
+		assert.Equal(t, "błąd bazy danych", c.Localize(err, "pl"))
+	})
+
+	t.Run("requested language before fallback", func(t *testing.T) {
+		// --- Given ---
+		c := NewCatalog("en")
+		c.Add("en", "ECOuter", "outer en")
+		c.Add("pl", "ECInner", "inner pl")
+		c.Add("en", "ECInner", "inner en")
+		e0 := New("em0", "ECInner")
+		err := Wrap(fmt.Errorf("w: %w", e0), "ECOuter")
+
+		// --- Then ---
+		assert.Equal(t, "inner pl", c.Localize(err, "pl"))
+		assert.Equal(t, "inner pl", c.Localize(err, "pl-PL"))
+		assert.Equal(t, "outer en", c.Localize(err, "de"))
+		assert.Equal(t, "outer en", c.Localize(err, "de", "en"))
+	})
+
+	t.Run("public message when not found", func(t *testing.T) {
+		// --- Given ---
+		err := New("pq: no rows", "ECUnknown").SetPublic("not found")
+
+		// --- Then ---
+		assert.Equal(t, "not found", c.Localize(err, "pl"))
+		assert.Equal(t, PublicMsgDefault, c.Localize(errors.New("em0"), "pl"))
+		assert.Equal(t, PublicMsgDefault, c.Localize(nil, "pl"))
+	})
+}
+
+func Test_Catalog_LocalizeAccept(t *testing.T) {
+	// --- Given ---
+	c := NewCatalog()
+	c.Add("en", "ECode", "en msg")
+	c.Add("pl", "ECode", "pl msg")
+	err := New("em0", "ECode")
+
+	// --- When ---
+	have := c.LocalizeAccept(err, "de-DE, en;q=0.5, pl;q=0.8")
+
+	// --- Then ---
+	assert.Equal(t, "pl msg", have)
+}
+
+func Test_Localize(t *testing.T) {
+	// --- Given ---
+	c := NewCatalog()
+	c.Add("pl", "ECode", "pl msg")
+	setCatalog(t, c)
+	err := New("em0", "ECode")
+
+	// --- Then ---
+	assert.Same(t, c, GetCatalog())
+	assert.Equal(t, "pl msg", Localize(err, "pl"))
+	assert.Equal(t, "pl msg", LocalizeAccept(err, "pl"))
+	assert.Equal(t, PublicMsgDefault, Localize(err, "en"))
+}
+
+func Test_ParseAcceptLanguage(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		exp    []string
+		header string
+	}{
+		{"empty", []string{}, ""},
+		{"single", []string{"pl"}, "pl"},
+		{"in order", []string{"pl", "en"}, "pl,en"},
+		{
+			"quality",
+			[]string{"fr-CH", "fr", "en", "de"},
+			"de;q=0.7, fr;q=0.9, fr-CH, en;q=0.8",
+		},
+		{"same quality keeps order", []string{"en", "pl"}, "en;q=0.5, pl;q=0.5"},
+		{"zero quality", []string{"en"}, "pl;q=0, en"},
+		{"wildcard", []string{"en"}, "en, *;q=0.1"},
+		{"invalid quality", []string{"en"}, "pl;q=abc, en"},
+		{"spaces", []string{"pl", "en"}, " pl , en ;q=0.5 "},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			assert.Equal(t, tc.exp, ParseAcceptLanguage(tc.header))
+		})
+	}
+}
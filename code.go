@@ -0,0 +1,103 @@
+package zrr
+
+import (
+	"slices"
+	"strings"
+)
+
+// CodeSep is the separator between segments of hierarchical error codes,
+// e.g. "db.conn.timeout".
+const CodeSep = "."
+
+// Codes returns every non-empty error code found in the err chain. The codes
+// are returned in the order they are found when walking the chain, each code
+// is returned only once.
+func Codes(err error) []string {
+	var codes []string
+	for e := range Chain(err) {
+		if e.code != "" && !slices.Contains(codes, e.code) {
+			codes = append(codes, e.code)
+		}
+	}
+	return codes
+}
+
+// CodeHistory returns the codes assigned to the error in the order they were
+// assigned, the original code first and the current code last. Codes are
+// recorded when the error is re-coded with Wrap or WrapMsg.
+func (e *Error) CodeHistory() []string {
+	if e.code == "" {
+		return slices.Clone(e.hist)
+	}
+	return append(slices.Clone(e.hist), e.code)
+}
+
+// HasCodeHistory returns true if error err is instance of Error and any of
+// the codes is its current code or a code it had before it was re-coded.
+func HasCodeHistory(err error, codes ...string) bool {
+	if e, ok := asError(err); ok {
+		for _, code := range codes {
+			if code == e.code || slices.Contains(e.hist, code) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// HasCodePrefix returns true if any error code in the err chain is equal to
+// prefix or starts with prefix followed by CodeSep. The prefix is matched on
+// whole segments, so "db.conn" matches "db.conn.timeout" but not
+// "db.connection".
+func HasCodePrefix(err error, prefix string) bool {
+	if prefix == "" {
+		return false
+	}
+	for e := range Chain(err) {
+		if e.code == prefix || strings.HasPrefix(e.code, prefix+CodeSep) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchCode returns true if any error code in the err chain matches the
+// pattern. The pattern is a hierarchical code where the segment "*" matches
+// exactly one code segment and the segment "**" matches zero or more code
+// segments, e.g. "db.*.timeout" or "db.**".
+func MatchCode(err error, pattern string) bool {
+	pat := strings.Split(pattern, CodeSep)
+	for e := range Chain(err) {
+		if e.code != "" && matchSegments(strings.Split(e.code, CodeSep), pat) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSegments returns true if code segments match pattern segments.
+func matchSegments(code, pat []string) bool {
+	for len(pat) > 0 {
+		switch pat[0] {
+		case "**":
+			for i := 0; i <= len(code); i++ {
+				if matchSegments(code[i:], pat[1:]) {
+					return true
+				}
+			}
+			return false
+
+		case "*":
+			if len(code) == 0 {
+				return false
+			}
+
+		default:
+			if len(code) == 0 || code[0] != pat[0] {
+				return false
+			}
+		}
+		code, pat = code[1:], pat[1:]
+	}
+	return len(code) == 0
+}
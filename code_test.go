@@ -0,0 +1,312 @@
+package zrr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+)
+
+func Test_Codes(t *testing.T) {
+	t.Run("chain", func(t *testing.T) {
+		// --- Given ---
+		e0 := New("em0", "db.conn.timeout")
+		e1 := Wrap(fmt.Errorf("w: %w", e0), "repo.get")
+		err := fmt.Errorf("w: %w", WrapMsg(e1, "handler", "api.user"))
+
+		// --- When ---
+		have := Codes(err)
+
+		// --- Then ---
+		assert.Equal(t, []string{"api.user", "db.conn.timeout"}, have)
+	})
+
+	t.Run("joined", func(t *testing.T) {
+		// --- Given ---
+		err := errors.Join(New("em0", "ECode0"), New("em1"), New("em2", "ECode2"))
+
+		// --- When ---
+		have := Codes(err)
+
+		// --- Then ---
+		assert.Equal(t, []string{"ECode0", "ECode2"}, have)
+	})
+
+	t.Run("duplicates", func(t *testing.T) {
+		// --- Given ---
+		err := Imm("em0", "ECode").Str("key", "val")
+
+		// --- When ---
+		have := Codes(err)
+
+		// --- Then ---
+		assert.Equal(t, []string{"ECode"}, have)
+	})
+
+	t.Run("no codes", func(t *testing.T) {
+		assert.Nil(t, Codes(New("em0")))
+		assert.Nil(t, Codes(errors.New("em0")))
+		assert.Nil(t, Codes(nil))
+	})
+}
+
+func Test_HasCodePrefix(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		exp    bool
+		err    error
+		prefix string
+	}{
+		{"exact", true, New("em0", "db.conn"), "db.conn"},
+		{"parent", true, New("em0", "db.conn.timeout"), "db.conn"},
+		{"root", true, New("em0", "db.conn.timeout"), "db"},
+		{"partial segment", false, New("em0", "db.connection"), "db.conn"},
+		{"child", false, New("em0", "db"), "db.conn"},
+		{"deeper in chain", true, Wrap(fmt.Errorf("w: %w", New("em0", "db.conn.refused")), "api"), "db.conn"},
+		{"wrapped", true, fmt.Errorf("w: %w", New("em0", "db.conn")), "db"},
+		{"empty prefix", false, New("em0", "db"), ""},
+		{"no code", false, New("em0"), "db"},
+		{"std error", false, errors.New("em0"), "db"},
+		{"nil", false, nil, "db"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			assert.Equal(t, tc.exp, HasCodePrefix(tc.err, tc.prefix))
+		})
+	}
+}
+
+func Test_MatchCode(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		exp     bool
+		code    string
+		pattern string
+	}{
+		{"exact", true, "db.conn.timeout", "db.conn.timeout"},
+		{"different", false, "db.conn.timeout", "db.conn.refused"},
+		{"shorter pattern", false, "db.conn.timeout", "db.conn"},
+		{"longer pattern", false, "db.conn", "db.conn.timeout"},
+		{"star last", true, "db.conn.timeout", "db.conn.*"},
+		{"star middle", true, "db.conn.timeout", "db.*.timeout"},
+		{"star first", true, "db.conn.timeout", "*.conn.timeout"},
+		{"star one segment only", false, "db.conn.timeout", "db.*"},
+		{"star requires segment", false, "db.conn", "db.conn.*"},
+		{"double star last", true, "db.conn.timeout", "db.**"},
+		{"double star zero segments", true, "db", "db.**"},
+		{"double star middle", true, "db.conn.pool.timeout", "db.**.timeout"},
+		{"double star middle zero", true, "db.timeout", "db.**.timeout"},
+		{"double star first", true, "db.conn.timeout", "**.timeout"},
+		{"double star only", true, "db.conn.timeout", "**"},
+		{"double star no match", false, "db.conn.refused", "db.**.timeout"},
+		{"mixed", true, "db.conn.pool.timeout", "*.conn.**"},
+		{"plain code", true, "ECode", "ECode"},
+		{"plain code star", true, "ECode", "*"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			assert.Equal(t, tc.exp, MatchCode(New("em0", tc.code), tc.pattern))
+		})
+	}
+}
+
+func Test_MatchCode_chain(t *testing.T) {
+	// --- Given ---
+	e0 := New("em0", "db.conn.timeout")
+	err := Wrap(fmt.Errorf("w: %w", e0), "api.user")
+
+	// --- Then ---
+	assert.True(t, MatchCode(err, "api.*"))
+	assert.True(t, MatchCode(err, "db.*.timeout"))
+	assert.False(t, MatchCode(err, "cache.**"))
+}
+
+func Test_MatchCode_noCode(t *testing.T) {
+	assert.False(t, MatchCode(New("em0"), "**"))
+	assert.False(t, MatchCode(errors.New("em0"), "**"))
+	assert.False(t, MatchCode(nil, "**"))
+}
+
+func Test_Error_CodeHistory(t *testing.T) {
+	t.Run("not re-coded", func(t *testing.T) {
+		// --- Given ---
+		err := New("em0", "ECDiskFull")
+
+		// --- When ---
+		have := err.CodeHistory()
+
+		// --- Then ---
+		assert.Equal(t, []string{"ECDiskFull"}, have)
+	})
+
+	t.Run("no code", func(t *testing.T) {
+		// --- Given ---
+		err := New("em0")
+
+		// --- When ---
+		have := err.CodeHistory()
+
+		// --- Then ---
+		assert.Nil(t, have)
+	})
+
+	t.Run("re-coded with Wrap", func(t *testing.T) {
+		// --- Given ---
+		err := New("em0", "ECDiskFull")
+
+		// --- When ---
+		have := Wrap(Wrap(err, "ECSaveFailed"), "ECRequest")
+
+		// --- Then ---
+		assert.Same(t, err, have)
+		assert.Equal(t, "ECRequest", have.ErrCode())
+		want := []string{"ECDiskFull", "ECSaveFailed", "ECRequest"}
+		assert.Equal(t, want, have.CodeHistory())
+	})
+
+	t.Run("re-coded with WrapMsg", func(t *testing.T) {
+		// --- Given ---
+		err := New("em0", "ECDiskFull")
+
+		// --- When ---
+		have := WrapMsg(err, "save", "ECSaveFailed")
+
+		// --- Then ---
+		assert.Equal(t, []string{"ECDiskFull", "ECSaveFailed"}, have.CodeHistory())
+	})
+
+	t.Run("same code is not recorded", func(t *testing.T) {
+		// --- Given ---
+		err := New("em0", "ECDiskFull")
+
+		// --- When ---
+		have := Wrap(err, "ECDiskFull")
+
+		// --- Then ---
+		assert.Equal(t, []string{"ECDiskFull"}, have.CodeHistory())
+	})
+
+	t.Run("code set on error without code", func(t *testing.T) {
+		// --- Given ---
+		err := New("em0")
+
+		// --- When ---
+		have := Wrap(err, "ECSaveFailed")
+
+		// --- Then ---
+		assert.Equal(t, []string{"ECSaveFailed"}, have.CodeHistory())
+	})
+
+	t.Run("immutable", func(t *testing.T) {
+		// --- Given ---
+		err := Imm("em0", "ECDiskFull")
+
+		// --- When ---
+		have := Wrap(err, "ECSaveFailed")
+
+		// --- Then ---
+		assert.NotSame(t, err, have)
+		assert.Equal(t, []string{"ECDiskFull", "ECSaveFailed"}, have.CodeHistory())
+		assert.Equal(t, []string{"ECDiskFull"}, err.CodeHistory())
+	})
+
+	t.Run("immutable with WrapMsg", func(t *testing.T) {
+		// --- Given ---
+		err := Imm("em0", "ECDiskFull")
+
+		// --- When ---
+		have := WrapMsg(err, "save", "ECSaveFailed")
+
+		// --- Then ---
+		assert.Equal(t, []string{"ECDiskFull", "ECSaveFailed"}, have.CodeHistory())
+	})
+
+	t.Run("clone does not share history", func(t *testing.T) {
+		// --- Given ---
+		err := Wrap(New("em0", "ECDiskFull"), "ECSaveFailed")
+		cpy := err.Clone()
+
+		// --- When ---
+		Wrap(cpy, "ECRequest")
+
+		// --- Then ---
+		assert.Equal(t, []string{"ECDiskFull", "ECSaveFailed"}, err.CodeHistory())
+		want := []string{"ECDiskFull", "ECSaveFailed", "ECRequest"}
+		assert.Equal(t, want, cpy.CodeHistory())
+	})
+}
+
+func Test_HasCodeHistory(t *testing.T) {
+	// --- Given ---
+	err := Wrap(New("em0", "ECDiskFull"), "ECSaveFailed")
+
+	// --- Then ---
+	assert.True(t, HasCodeHistory(err, "ECSaveFailed"))
+	assert.True(t, HasCodeHistory(err, "ECDiskFull"))
+	assert.True(t, HasCodeHistory(fmt.Errorf("w: %w", err), "ECDiskFull"))
+	assert.True(t, HasCodeHistory(err, "ECOther", "ECDiskFull"))
+	assert.False(t, HasCodeHistory(err, "ECOther"))
+	assert.False(t, HasCodeHistory(errors.New("em0"), "ECDiskFull"))
+	assert.False(t, HasCodeHistory(nil, "ECDiskFull"))
+
+	assert.False(t, HasCode(err, "ECDiskFull"))
+}
+
+func Test_Error_MarshalJSON_codeHistory(t *testing.T) {
+	// --- Given ---
+	err := Wrap(New("em0", "ECDiskFull"), "ECSaveFailed")
+
+	// --- When ---
+	data, jErr := json.Marshal(err)
+
+	// --- Then ---
+	assert.NoError(t, jErr)
+	want := `{
+		"error":"em0",
+		"code":"ECSaveFailed",
+		"code_history":["ECDiskFull","ECSaveFailed"]
+	}`
+	assert.JSON(t, want, string(data))
+}
+
+func Test_Error_UnmarshalJSON_codeHistory(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		// --- Given ---
+		data := `{
+			"error":"em0",
+			"code":"ECSaveFailed",
+			"code_history":["ECDiskFull","ECSaveFailed"]
+		}`
+		err := &Error{}
+
+		// --- When ---
+		jErr := json.Unmarshal([]byte(data), err)
+
+		// --- Then ---
+		assert.NoError(t, jErr)
+		assert.Equal(t, "ECSaveFailed", err.ErrCode())
+		want := []string{"ECDiskFull", "ECSaveFailed"}
+		assert.Equal(t, want, err.CodeHistory())
+		assert.True(t, HasCodeHistory(err, "ECDiskFull"))
+	})
+
+	t.Run("history without current code", func(t *testing.T) {
+		// --- Given ---
+		data := `{"error":"em0","code":"ECSaveFailed","code_history":["ECDiskFull"]}`
+		err := &Error{}
+
+		// --- When ---
+		jErr := json.Unmarshal([]byte(data), err)
+
+		// --- Then ---
+		assert.NoError(t, jErr)
+		want := []string{"ECDiskFull", "ECSaveFailed"}
+		assert.Equal(t, want, err.CodeHistory())
+	})
+}
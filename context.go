@@ -0,0 +1,110 @@
+package zrr
+
+import (
+	"context"
+	"iter"
+	"sync"
+)
+
+// Extractor is a function contributing metadata found in ctx to the error.
+// It returns the error with metadata added, the returned instance might be
+// different from e if it's immutable.
+type Extractor func(ctx context.Context, e *Error) *Error
+
+// extractors are the registered context metadata extractors.
+var (
+	extractorsMx sync.RWMutex
+	extractors   []Extractor
+)
+
+// AddExtractor registers the context metadata extractor. The extractors are
+// called in the order they were registered by NewCtx, WrapCtx and
+// Error.FromCtx after metadata set with WithMeta was added.
+func AddExtractor(fn Extractor) {
+	extractorsMx.Lock()
+	defer extractorsMx.Unlock()
+	extractors = append(extractors, fn)
+}
+
+// ctxKey is the context key for error metadata.
+type ctxKey struct{}
+
+// ctxMeta represents error metadata stored in the context. Once stored in
+// the context it's never changed.
+type ctxMeta struct {
+	keys []string       // Metadata keys in the insertion order.
+	meta map[string]any // Key value metadata.
+}
+
+// WithMeta returns a copy of ctx carrying error metadata key with value v.
+// The metadata added to the parent context is preserved, the key with the
+// same name is overwritten.
+func WithMeta(ctx context.Context, key string, v any) context.Context {
+	cm := &ctxMeta{meta: make(map[string]any)}
+	if pm, _ := ctx.Value(ctxKey{}).(*ctxMeta); pm != nil {
+		cm.keys = make([]string, len(pm.keys), len(pm.keys)+1)
+		copy(cm.keys, pm.keys)
+		for k, val := range pm.meta {
+			cm.meta[k] = val
+		}
+	}
+	if _, ok := cm.meta[key]; !ok {
+		cm.keys = append(cm.keys, key)
+	}
+	cm.meta[key] = v
+	return context.WithValue(ctx, ctxKey{}, cm)
+}
+
+// CtxFields returns an iterator over error metadata key value pairs set on
+// ctx with WithMeta. The keys are iterated in the order they were added.
+func CtxFields(ctx context.Context) iter.Seq2[string, any] {
+	return func(yield func(string, any) bool) {
+		cm, _ := ctx.Value(ctxKey{}).(*ctxMeta)
+		if cm == nil {
+			return
+		}
+		for _, key := range cm.keys {
+			if !yield(key, cm.meta[key]) {
+				return
+			}
+		}
+	}
+}
+
+// NewCtx is a constructor returning new Error instance with metadata from
+// ctx. See Error.FromCtx for details.
+func NewCtx(ctx context.Context, msg string, code ...string) *Error {
+	return New(msg, code...).FromCtx(ctx)
+}
+
+// WrapCtx wraps err in Error instance the same way Wrap does and adds
+// metadata from ctx. See Error.FromCtx for details. It returns nil if err
+// is nil or typed nil (nil pointer).
+func WrapCtx(ctx context.Context, err error, code ...string) *Error {
+	return Wrap(err, code...).FromCtx(ctx)
+}
+
+// FromCtx adds metadata set on ctx with WithMeta and metadata provided by
+// registered extractors. Keys which already exist on the error are not
+// overwritten by the context metadata. Extractors returning nil are ignored.
+// The returned instance might be different from the one this method is
+// called if the error is immutable.
+func (e *Error) FromCtx(ctx context.Context) *Error {
+	if e == nil || ctx == nil {
+		return e
+	}
+	ne := e
+	if cm, _ := ctx.Value(ctxKey{}).(*ctxMeta); cm != nil {
+		ne, _ = ne.merge(cm.keys, cm.meta, MergeKeep)
+	}
+
+	extractorsMx.RLock()
+	fns := extractors
+	extractorsMx.RUnlock()
+	for _, fn := range fns {
+		if re := fn(ctx, ne); !isNil(re) {
+			ne = re
+		}
+	}
+	return ne
+}
@@ -0,0 +1,228 @@
+package zrr
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+)
+
+// resetExtractors removes extractors registered during the test.
+func resetExtractors(t *testing.T) {
+	t.Helper()
+	extractorsMx.Lock()
+	saved := extractors
+	extractorsMx.Unlock()
+	t.Cleanup(func() {
+		extractorsMx.Lock()
+		extractors = saved
+		extractorsMx.Unlock()
+	})
+}
+
+func Test_WithMeta(t *testing.T) {
+	t.Run("add", func(t *testing.T) {
+		// --- When ---
+		ctx := WithMeta(context.Background(), "rid", "r1")
+		ctx = WithMeta(ctx, "tid", 2)
+
+		// --- Then ---
+		var keys []string
+		for key := range CtxFields(ctx) {
+			keys = append(keys, key)
+		}
+		assert.Equal(t, []string{"rid", "tid"}, keys)
+		assert.Equal(t, map[string]any{"rid": "r1", "tid": 2}, maps.Collect(CtxFields(ctx)))
+	})
+
+	t.Run("parent not changed", func(t *testing.T) {
+		// --- Given ---
+		parent := WithMeta(context.Background(), "rid", "r1")
+
+		// --- When ---
+		ctx := WithMeta(parent, "rid", "r2")
+		ctx = WithMeta(ctx, "tid", 2)
+
+		// --- Then ---
+		assert.Equal(t, map[string]any{"rid": "r1"}, maps.Collect(CtxFields(parent)))
+		assert.Equal(t, map[string]any{"rid": "r2", "tid": 2}, maps.Collect(CtxFields(ctx)))
+	})
+}
+
+func Test_CtxFields(t *testing.T) {
+	t.Run("no metadata", func(t *testing.T) {
+		// --- When ---
+		got := maps.Collect(CtxFields(context.Background()))
+
+		// --- Then ---
+		assert.Len(t, 0, got)
+	})
+
+	t.Run("break", func(t *testing.T) {
+		// --- Given ---
+		ctx := WithMeta(context.Background(), "rid", "r1")
+		ctx = WithMeta(ctx, "tid", 2)
+
+		// --- When ---
+		var keys []string
+		for key := range CtxFields(ctx) {
+			keys = append(keys, key)
+			break
+		}
+
+		// --- Then ---
+		assert.Equal(t, []string{"rid"}, keys)
+	})
+}
+
+func Test_AddExtractor(t *testing.T) {
+	// --- Given ---
+	resetExtractors(t)
+	type traceKey struct{}
+	ctx := context.WithValue(context.Background(), traceKey{}, "t1")
+
+	// --- When ---
+	AddExtractor(func(ctx context.Context, e *Error) *Error {
+		if tid, ok := ctx.Value(traceKey{}).(string); ok {
+			return e.Str("trace_id", tid)
+		}
+		return e
+	})
+	AddExtractor(func(ctx context.Context, e *Error) *Error {
+		return e.Str("second", "yes")
+	})
+
+	// --- Then ---
+	err := NewCtx(ctx, "em0")
+	assert.Equal(t, []string{"trace_id", "second"}, err.keys)
+	assert.Equal(t, map[string]any{"trace_id": "t1", "second": "yes"}, err.GetMetadata())
+}
+
+func Test_NewCtx(t *testing.T) {
+	// --- Given ---
+	ctx := WithMeta(context.Background(), "rid", "r1")
+
+	// --- When ---
+	err := NewCtx(ctx, "em0", "ECode")
+
+	// --- Then ---
+	assert.Equal(t, "em0", err.Error())
+	assert.Equal(t, "ECode", err.ErrCode())
+	assert.Equal(t, map[string]any{"rid": "r1"}, err.GetMetadata())
+}
+
+func Test_WrapCtx(t *testing.T) {
+	t.Run("std error", func(t *testing.T) {
+		// --- Given ---
+		ctx := WithMeta(context.Background(), "rid", "r1")
+		e := errors.New("std error")
+
+		// --- When ---
+		err := WrapCtx(ctx, e, "ECode")
+
+		// --- Then ---
+		assert.Same(t, e, err.Unwrap())
+		assert.Equal(t, "ECode", err.ErrCode())
+		assert.Equal(t, map[string]any{"rid": "r1"}, err.GetMetadata())
+	})
+
+	t.Run("existing keys not overwritten", func(t *testing.T) {
+		// --- Given ---
+		ctx := WithMeta(context.Background(), "rid", "r1")
+		ctx = WithMeta(ctx, "tid", "t1")
+		e := New("em0").Str("rid", "r0")
+
+		// --- When ---
+		err := WrapCtx(ctx, e)
+
+		// --- Then ---
+		assert.Same(t, e, err)
+		assert.Equal(t, map[string]any{"rid": "r0", "tid": "t1"}, err.GetMetadata())
+		assert.Equal(t, []string{"rid", "tid"}, err.keys)
+	})
+
+	t.Run("immutable", func(t *testing.T) {
+		// --- Given ---
+		ctx := WithMeta(context.Background(), "rid", "r1")
+		imm := Imm("em0", "ECode")
+
+		// --- When ---
+		err := WrapCtx(ctx, imm)
+
+		// --- Then ---
+		assert.NotSame(t, imm, err)
+		assert.ErrorIs(t, imm, err)
+		assert.Len(t, 0, imm.GetMetadata())
+		assert.Equal(t, map[string]any{"rid": "r1"}, err.GetMetadata())
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		// --- When ---
+		err := WrapCtx(context.Background(), nil)
+
+		// --- Then ---
+		assert.Nil(t, err)
+	})
+
+	t.Run("typed nil", func(t *testing.T) {
+		// --- Given ---
+		var ne *nilErr
+
+		// --- When ---
+		err := WrapCtx(context.Background(), ne)
+
+		// --- Then ---
+		assert.Nil(t, err)
+	})
+}
+
+func Test_Error_FromCtx(t *testing.T) {
+	t.Run("no metadata", func(t *testing.T) {
+		// --- Given ---
+		e := New("em0")
+
+		// --- When ---
+		err := e.FromCtx(context.Background())
+
+		// --- Then ---
+		assert.Same(t, e, err)
+		assert.Len(t, 0, err.GetMetadata())
+	})
+
+	t.Run("extractor returns nil", func(t *testing.T) {
+		// --- Given ---
+		resetExtractors(t)
+		AddExtractor(func(ctx context.Context, e *Error) *Error {
+			return e.Str("first", "yes")
+		})
+		AddExtractor(func(ctx context.Context, e *Error) *Error { return nil })
+		AddExtractor(func(ctx context.Context, e *Error) *Error {
+			var ne *Error
+			return ne
+		})
+		AddExtractor(func(ctx context.Context, e *Error) *Error {
+			return e.Str("last", "yes")
+		})
+
+		// --- When ---
+		err := NewCtx(context.Background(), "em0")
+
+		// --- Then ---
+		assert.NotNil(t, err)
+		assert.Equal(t, []string{"first", "last"}, err.keys)
+	})
+
+	t.Run("nil context", func(t *testing.T) {
+		// --- Given ---
+		var ctx context.Context
+		e := New("em0")
+
+		// --- When ---
+		err := e.FromCtx(ctx)
+
+		// --- Then ---
+		assert.Same(t, e, err)
+	})
+}
@@ -0,0 +1,69 @@
+package zrr
+
+import (
+	"errors"
+	"io"
+)
+
+// ECClose represents close error code.
+const ECClose = "ECClose"
+
+// Annotate is meant to be deferred, it decorates the error err points to
+// with fn when the error is not nil. When the error is not an Error instance
+// it's wrapped with Wrap before calling fn. Immutable errors are decorated
+// the same way as with the other methods adding metadata - on their copy.
+// Typed nil (nil pointer) errors are replaced with nil. When fn returns nil
+// or typed nil the error is left unchanged, the error is never dropped.
+//
+//	func load(id int) (err error) {
+//	    defer zrr.Annotate(&err, func(e *zrr.Error) *zrr.Error {
+//	        return e.Int("id", id)
+//	    })
+//	    ...
+//	}
+func Annotate(err *error, fn func(*Error) *Error) {
+	if isNil(*err) {
+		*err = nil
+		return
+	}
+	if e := fn(Wrap(*err)); !isNil(e) {
+		*err = e
+	}
+}
+
+// Close is meant to be deferred, it closes c and when closing fails, the
+// close error is wrapped in Error instance with ECClose code and "close"
+// message prefix and joined with the error err points to. The hooks, if
+// provided, are called in order with the close error and may add metadata
+// to it, a hook returning nil discards the close error. The metadata of the
+// close error is kept separate from the metadata of the error err points to.
+//
+//	func read(pth string) (err error) {
+//	    fil, err := os.Open(pth)
+//	    if err != nil {
+//	        return err
+//	    }
+//	    defer zrr.Close(&err, fil)
+//	    ...
+//	}
+func Close(err *error, c io.Closer, hooks ...func(*Error) *Error) {
+	cerr := c.Close()
+	if isNil(cerr) {
+		return
+	}
+	e := base(cerr, false, ECClose)
+	e.msg = "close"
+	for _, hook := range hooks {
+		if e = hook(e); isNil(e) {
+			if isNil(*err) {
+				*err = nil
+			}
+			return
+		}
+	}
+	if !isNil(*err) {
+		*err = errors.Join(*err, e)
+		return
+	}
+	*err = e
+}
@@ -0,0 +1,285 @@
+package zrr
+
+import (
+	"errors"
+	"maps"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+)
+
+func Test_Annotate(t *testing.T) {
+	t.Run("std error", func(t *testing.T) {
+		// --- Given ---
+		e := errors.New("std error")
+		fn := func() (err error) {
+			defer Annotate(&err, func(e *Error) *Error { return e.Int("id", 1) })
+			return e
+		}
+
+		// --- When ---
+		err := fn()
+
+		// --- Then ---
+		assert.ErrorIs(t, e, err)
+		id, _ := GetInt(err, "id")
+		assert.Equal(t, 1, id)
+	})
+
+	t.Run("mutable error", func(t *testing.T) {
+		// --- Given ---
+		e := New("em0", "ECode")
+		fn := func() (err error) {
+			defer Annotate(&err, func(e *Error) *Error { return e.Int("id", 1) })
+			return e
+		}
+
+		// --- When ---
+		err := fn()
+
+		// --- Then ---
+		assert.Same(t, e, err)
+		assert.True(t, HasKey(e, "id"))
+	})
+
+	t.Run("immutable error", func(t *testing.T) {
+		// --- Given ---
+		imm := Imm("em0", "ECode")
+		fn := func() (err error) {
+			defer Annotate(&err, func(e *Error) *Error { return e.Int("id", 1) })
+			return imm
+		}
+
+		// --- When ---
+		err := fn()
+
+		// --- Then ---
+		assert.NotSame(t, imm, err)
+		assert.ErrorIs(t, imm, err)
+		assert.True(t, HasKey(err, "id"))
+		assert.Len(t, 0, imm.GetMetadata())
+	})
+
+	t.Run("typed nil", func(t *testing.T) {
+		// --- Given ---
+		var called bool
+		fn := func() (err error) {
+			defer Annotate(&err, func(e *Error) *Error { called = true; return e })
+			var ne *nilErr
+			return ne
+		}
+
+		// --- When ---
+		err := fn()
+
+		// --- Then ---
+		assert.True(t, err == nil)
+		assert.False(t, called)
+	})
+
+	t.Run("fn returns nil", func(t *testing.T) {
+		// --- Given ---
+		e := errors.New("std error")
+		fn := func() (err error) {
+			defer Annotate(&err, func(e *Error) *Error { return nil })
+			return e
+		}
+
+		// --- When ---
+		err := fn()
+
+		// --- Then ---
+		assert.Same(t, e, err)
+	})
+
+	t.Run("fn returns typed nil", func(t *testing.T) {
+		// --- Given ---
+		e := New("em0")
+		fn := func() (err error) {
+			defer Annotate(&err, func(e *Error) *Error {
+				var ne *Error
+				return ne
+			})
+			return e
+		}
+
+		// --- When ---
+		err := fn()
+
+		// --- Then ---
+		assert.Same(t, e, err)
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		// --- Given ---
+		var called bool
+		fn := func() (err error) {
+			defer Annotate(&err, func(e *Error) *Error { called = true; return e })
+			return nil
+		}
+
+		// --- When ---
+		err := fn()
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.False(t, called)
+	})
+}
+
+// closer is a test io.Closer returning err.
+type closer struct{ err error }
+
+func (c closer) Close() error { return c.err }
+
+func Test_Close(t *testing.T) {
+	t.Run("close error", func(t *testing.T) {
+		// --- Given ---
+		ce := errors.New("close failed")
+		fn := func() (err error) {
+			defer Close(&err, closer{err: ce})
+			return nil
+		}
+
+		// --- When ---
+		err := fn()
+
+		// --- Then ---
+		assert.ErrorEqual(t, "close: close failed", err)
+		assert.ErrorIs(t, ce, err)
+		assert.True(t, HasCode(err, ECClose))
+	})
+
+	t.Run("joined with error", func(t *testing.T) {
+		// --- Given ---
+		ce := New("close failed").Str("path", "/tmp/a")
+		e := New("read failed", "ECRead").Int("offset", 10)
+		fn := func() (err error) {
+			defer Close(&err, closer{err: ce})
+			return e
+		}
+
+		// --- When ---
+		err := fn()
+
+		// --- Then ---
+		assert.ErrorEqual(t, "read failed\nclose: close failed", err)
+		assert.ErrorIs(t, e, err)
+		assert.ErrorIs(t, ce, err)
+		assert.Equal(t, map[string]any{"offset": 10}, e.GetMetadata())
+		assert.Equal(t, map[string]any{"path": "/tmp/a"}, ce.GetMetadata())
+	})
+
+	t.Run("hooks", func(t *testing.T) {
+		// --- Given ---
+		fn := func() (err error) {
+			defer Close(&err, closer{err: errors.New("close failed")},
+				func(e *Error) *Error { return e.Str("path", "/tmp/a") })
+			return nil
+		}
+
+		// --- When ---
+		err := fn()
+
+		// --- Then ---
+		pth, _ := GetStr(err, "path")
+		assert.Equal(t, "/tmp/a", pth)
+	})
+
+	t.Run("immutable close error", func(t *testing.T) {
+		// --- Given ---
+		ce := Scope("fs").Imm("close failed {component}")
+		fn := func() (err error) {
+			defer Close(&err, closer{err: ce})
+			return nil
+		}
+
+		// --- When ---
+		err := fn()
+
+		// --- Then ---
+		assert.ErrorEqual(t, "close: close failed fs", err)
+		assert.ErrorIs(t, ce, err)
+		have, ok := GetList(err, KeyComponent)
+		assert.True(t, ok)
+		assert.Equal(t, []any{"fs"}, have)
+		assert.Equal(t, map[string]any{KeyComponent: "fs"}, maps.Collect(AllFields(err)))
+	})
+
+	t.Run("hook returns nil", func(t *testing.T) {
+		// --- Given ---
+		fn := func() (err error) {
+			defer Close(&err, closer{err: errors.New("close failed")},
+				func(e *Error) *Error { return nil },
+				func(e *Error) *Error { return e.Str("path", "/tmp/a") })
+			return nil
+		}
+
+		// --- When ---
+		err := fn()
+
+		// --- Then ---
+		assert.True(t, err == nil)
+	})
+
+	t.Run("hook returns nil with error", func(t *testing.T) {
+		// --- Given ---
+		e := New("read failed")
+		fn := func() (err error) {
+			defer Close(&err, closer{err: errors.New("close failed")},
+				func(e *Error) *Error { return nil })
+			return e
+		}
+
+		// --- When ---
+		err := fn()
+
+		// --- Then ---
+		assert.Same(t, e, err)
+	})
+
+	t.Run("typed nil error", func(t *testing.T) {
+		// --- Given ---
+		fn := func() (err error) {
+			defer Close(&err, closer{err: errors.New("close failed")})
+			var ne *nilErr
+			return ne
+		}
+
+		// --- When ---
+		err := fn()
+
+		// --- Then ---
+		assert.ErrorEqual(t, "close: close failed", err)
+	})
+
+	t.Run("typed nil close error", func(t *testing.T) {
+		// --- Given ---
+		var ne *nilErr
+		fn := func() (err error) {
+			defer Close(&err, closer{err: ne})
+			return nil
+		}
+
+		// --- When ---
+		err := fn()
+
+		// --- Then ---
+		assert.NoError(t, err)
+	})
+
+	t.Run("no close error", func(t *testing.T) {
+		// --- Given ---
+		e := errors.New("std error")
+		fn := func() (err error) {
+			defer Close(&err, closer{})
+			return e
+		}
+
+		// --- When ---
+		err := fn()
+
+		// --- Then ---
+		assert.Same(t, e, err)
+	})
+}
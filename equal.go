@@ -0,0 +1,169 @@
+package zrr
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"time"
+)
+
+// Equal returns true if errors a and b are equal. See Diff for details.
+func Equal(a, b error) bool { return len(Diff(a, b)) == 0 }
+
+// Diff returns human-readable list of differences between errors a and b.
+// The errors are compared layer by layer along their chains (see Chain).
+// For each layer the error message, code and metadata are compared. Numeric
+// metadata values are compared by value regardless of their types, so the
+// integer 1 is equal to the float64 1 (useful after JSON round trip).
+// Errors without Error instances in their chains are compared by their
+// messages. It returns nil when the errors are equal.
+func Diff(a, b error) []string {
+	switch {
+	case isNil(a) && isNil(b):
+		return nil
+	case isNil(a):
+		return []string{"a is nil"}
+	case isNil(b):
+		return []string{"b is nil"}
+	}
+
+	as, bs := slices.Collect(Chain(a)), slices.Collect(Chain(b))
+	if len(as) == 0 || len(bs) == 0 {
+		if a.Error() != b.Error() {
+			return []string{fmt.Sprintf("message: %q != %q", a.Error(), b.Error())}
+		}
+		if len(as) != len(bs) {
+			return []string{fmt.Sprintf("chain length: %d != %d", len(as), len(bs))}
+		}
+		return nil
+	}
+
+	var diff []string
+	if len(as) != len(bs) {
+		diff = append(diff, fmt.Sprintf("chain length: %d != %d", len(as), len(bs)))
+	}
+	for i := range min(len(as), len(bs)) {
+		diff = append(diff, diffError(fmt.Sprintf("[%d] ", i), as[i], bs[i])...)
+	}
+	return diff
+}
+
+// diffError returns differences between a and b layers with each line
+// prefixed with prefix.
+func diffError(prefix string, a, b *Error) []string {
+	var diff []string
+	if a.Error() != b.Error() {
+		diff = append(diff, fmt.Sprintf("%smessage: %q != %q", prefix, a.Error(), b.Error()))
+	}
+	if a.code != b.code {
+		diff = append(diff, fmt.Sprintf("%scode: %q != %q", prefix, a.code, b.code))
+	}
+	for _, key := range a.metaKeys() {
+		av := a.meta[key]
+		bv, ok := b.meta[key]
+		if !ok {
+			diff = append(diff, fmt.Sprintf("%smeta %q: missing in b", prefix, key))
+			continue
+		}
+		if !valuesEqual(av, bv) {
+			diff = append(diff, fmt.Sprintf(
+				"%smeta %q: %s != %s", prefix, key, fmtValue(key, av), fmtValue(key, bv),
+			))
+		}
+	}
+	for _, key := range b.metaKeys() {
+		if _, ok := a.meta[key]; !ok {
+			diff = append(diff, fmt.Sprintf("%smeta %q: missing in a", prefix, key))
+		}
+	}
+	return diff
+}
+
+// fmtValue returns metadata value formatted for Diff output. Sensitive
+// values are masked.
+func fmtValue(key string, v any) string {
+	if v = Redact(key, v); v == Redacted {
+		return Redacted
+	}
+	if t, ok := v.(time.Time); ok {
+		return fmt.Sprintf("time.Time(%s)", t.Format(time.RFC3339Nano))
+	}
+	return fmt.Sprintf("%#v", v)
+}
+
+// valuesEqual returns true if metadata values a and b are equal. Numbers are
+// compared by value, lists element by element and times with time.Equal.
+func valuesEqual(a, b any) bool {
+	a, b = Reveal(a), Reveal(b)
+	if x, ok := a.(time.Time); ok {
+		y, ok := b.(time.Time)
+		return ok && x.Equal(y)
+	}
+	if isList(a) && isList(b) {
+		ae, be := listElems(a), listElems(b)
+		if len(ae) != len(be) {
+			return false
+		}
+		for i := range ae {
+			if !valuesEqual(ae[i], be[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	if equal, ok := numbersEqual(a, b); ok {
+		return equal
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// numbersEqual compares a and b by value when both are numbers. Integers are
+// compared exactly, when any of the values is a float both are compared as
+// float64. The second return value is false if any of the values is not
+// a number.
+func numbersEqual(a, b any) (bool, bool) {
+	av, bv := reflect.ValueOf(a), reflect.ValueOf(b)
+	ak, bk := numKind(av), numKind(bv)
+	switch {
+	case ak == reflect.Invalid || bk == reflect.Invalid:
+		return false, false
+	case ak == reflect.Int && bk == reflect.Int:
+		return av.Int() == bv.Int(), true
+	case ak == reflect.Uint && bk == reflect.Uint:
+		return av.Uint() == bv.Uint(), true
+	case ak == reflect.Int && bk == reflect.Uint:
+		return av.Int() >= 0 && uint64(av.Int()) == bv.Uint(), true
+	case ak == reflect.Uint && bk == reflect.Int:
+		return bv.Int() >= 0 && av.Uint() == uint64(bv.Int()), true
+	default:
+		return toFloat(av) == toFloat(bv), true
+	}
+}
+
+// numKind returns reflect.Int for signed integers, reflect.Uint for unsigned
+// integers, reflect.Float64 for floats and reflect.Invalid for other values.
+func numKind(v reflect.Value) reflect.Kind {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return reflect.Int
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		return reflect.Uint
+	case reflect.Float32, reflect.Float64:
+		return reflect.Float64
+	default:
+		return reflect.Invalid
+	}
+}
+
+// toFloat returns numeric value v as float64.
+func toFloat(v reflect.Value) float64 {
+	switch numKind(v) {
+	case reflect.Int:
+		return float64(v.Int())
+	case reflect.Uint:
+		return float64(v.Uint())
+	default:
+		return v.Float()
+	}
+}
@@ -0,0 +1,131 @@
+package zrr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ctx42/testing/pkg/assert"
+)
+
+func Test_Diff(t *testing.T) {
+	tim := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tt := []struct {
+		testN string
+
+		exp []string
+		a   error
+		b   error
+	}{
+		{"both nil", nil, nil, nil},
+		{"a nil", []string{"a is nil"}, nil, New("em0")},
+		{"b nil", []string{"b is nil"}, New("em0"), nil},
+		{"typed nil", nil, (*Error)(nil), nil},
+		{"equal", nil, New("em0", "ECode").Int("a", 1), New("em0", "ECode").Int("a", 1)},
+		{
+			"message",
+			[]string{`[0] message: "em0" != "em1"`},
+			New("em0"), New("em1"),
+		},
+		{
+			"code",
+			[]string{`[0] code: "ECode0" != "ECode1"`},
+			New("em0", "ECode0"), New("em0", "ECode1"),
+		},
+		{
+			"meta value",
+			[]string{`[0] meta "a": 1 != 2`},
+			New("em0").Int("a", 1), New("em0").Int("a", 2),
+		},
+		{
+			"meta type",
+			[]string{`[0] meta "a": "1" != 1`},
+			New("em0").Str("a", "1"), New("em0").Int("a", 1),
+		},
+		{
+			"meta missing",
+			[]string{`[0] meta "a": missing in b`, `[0] meta "b": missing in a`},
+			New("em0").Int("a", 1), New("em0").Int("b", 1),
+		},
+		{"numbers", nil, New("em0").Int("a", 1), New("em0").Float64("a", 1)},
+		{"int64", nil, New("em0").Int64("a", 1), New("em0").Int("a", 1)},
+		{
+			"numbers differ",
+			[]string{`[0] meta "a": 1 != 1.5`},
+			New("em0").Int("a", 1), New("em0").Float64("a", 1.5),
+		},
+		{"duration", nil, New("em0").Duration("a", time.Second), New("em0").Float64("a", 1e9)},
+		{"lists", nil, New("em0").AppendInt("a", 1), New("em0").Append("a", []any{1.0})},
+		{
+			"lists differ",
+			[]string{`[0] meta "a": []int{1} != []int{1, 2}`},
+			New("em0").AppendInt("a", 1), New("em0").AppendInt("a", 1).AppendInt("a", 2),
+		},
+		{"times", nil, New("em0").Time("a", tim), New("em0").Time("a", tim.In(time.Local))},
+		{
+			"times differ",
+			[]string{`[0] meta "a": time.Time(2020-01-02T03:04:05Z) != time.Time(2020-01-02T03:04:06Z)`},
+			New("em0").Time("a", tim), New("em0").Time("a", tim.Add(time.Second)),
+		},
+		{"secrets", nil, New("em0").SecretStr("a", "s"), New("em0").Str("a", "s")},
+		{
+			"secrets differ",
+			[]string{`[0] meta "a": [REDACTED] != [REDACTED]`},
+			New("em0").SecretStr("a", "s0"), New("em0").SecretStr("a", "s1"),
+		},
+		{
+			"chain length",
+			[]string{"chain length: 2 != 1", `[0] message: "w: em0" != "em0"`},
+			Wrap(fmt.Errorf("w: %w", New("em0"))), New("em0"),
+		},
+		{
+			"deeper layer",
+			[]string{`[1] code: "ECode0" != "ECode1"`},
+			Wrap(fmt.Errorf("w: %w", New("em0", "ECode0"))),
+			Wrap(fmt.Errorf("w: %w", New("em0", "ECode1"))),
+		},
+		{"std errors", nil, errors.New("em0"), errors.New("em0")},
+		{
+			"std errors differ",
+			[]string{`message: "em0" != "em1"`},
+			errors.New("em0"), errors.New("em1"),
+		},
+		{
+			"std error and Error",
+			[]string{"chain length: 0 != 1"},
+			errors.New("em0"), New("em0"),
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			assert.Equal(t, tc.exp, Diff(tc.a, tc.b))
+		})
+	}
+}
+
+func Test_Diff_JSONRoundTrip(t *testing.T) {
+	// --- Given ---
+	err := New("em0", "ECode").Int("a", 1).Int64("b", 2).AppendStr("c", "s")
+	data, jErr := json.Marshal(err)
+	assert.NoError(t, jErr)
+
+	// --- When ---
+	have := &Error{}
+	jErr = json.Unmarshal(data, have)
+
+	// --- Then ---
+	assert.NoError(t, jErr)
+	assert.Nil(t, Diff(err, have))
+}
+
+func Test_Equal(t *testing.T) {
+	assert.True(t, Equal(New("em0", "ECode").Int("a", 1), New("em0", "ECode").Float64("a", 1)))
+	assert.True(t, Equal(nil, nil))
+	assert.False(t, Equal(New("em0"), New("em0", "ECode")))
+	assert.False(t, Equal(New("em0").Int("a", 1), New("em0")))
+	assert.False(t, Equal(nil, New("em0")))
+}
@@ -5,9 +5,13 @@
 package zrr
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"iter"
+	"slices"
 	"time"
 )
 
@@ -32,9 +36,10 @@ const ECInvJSON = "ECInvJSON"
 // format error.
 var ErrInvJSON = Imm("invalid JSON", ECInvJSON)
 
-// Wrap wraps err in Error instance. It returns nil if err is nil.
+// Wrap wraps err in Error instance. It returns nil if err is nil or typed
+// nil (nil pointer).
 func Wrap(err error, code ...string) *Error {
-	if err == nil {
+	if isNil(err) {
 		return nil
 	}
 	if e, ok := err.(*Error); ok {
@@ -46,6 +51,91 @@ func Wrap(err error, code ...string) *Error {
 	return base(err, false, code...)
 }
 
+// WrapMsg wraps err in Error instance and prefixes its message with msg. The
+// error message is rendered as "msg: cause". It returns nil if err is nil.
+//
+// When err is a mutable Error instance it is changed in place - the message
+// prefix is added in front of already existing one and the code, if provided,
+// replaces the current one. When err is immutable Error instance a new
+// instance wrapping it is returned.
+//
+// Error code is optional, if more than one code is provided the first
+// one will be used. Initial metadata may be added with SetErrMetadata.
+// It returns nil if err is nil or typed nil (nil pointer).
+func WrapMsg(err error, msg string, code ...string) *Error {
+	return wrapMsg(err, msg, msg, code...)
+}
+
+// wrapMsg behaves the same way as WrapMsg. The format is the message prefix
+// before it was formatted, it's used instead of msg to compute fingerprints.
+func wrapMsg(err error, msg, format string, code ...string) *Error {
+	if isNil(err) {
+		return nil
+	}
+	if e, ok := err.(*Error); ok {
+		if e.imm {
+			ne := e.mutable()
+			ne.setMsg(msg, format)
+			if len(code) > 0 {
+				ne.recode(code[0])
+			}
+			return ne
+		}
+		if e.msg != "" {
+			msg = msg + ": " + e.msg
+			format = format + ": " + e.msgFormat()
+		}
+		e.setMsg(msg, format)
+		if len(code) > 0 {
+			return e.setCode(code[0])
+		}
+		return e
+	}
+	ne := base(err, false, code...)
+	ne.setMsg(msg, format)
+	return ne
+}
+
+// setMsg sets the message prefix and its format.
+func (e *Error) setMsg(msg, format string) {
+	e.msg = msg
+	e.msgFmt = ""
+	if format != msg {
+		e.msgFmt = format
+	}
+}
+
+// msgFormat returns the message prefix before it was formatted.
+func (e *Error) msgFormat() string {
+	if e.msgFmt != "" {
+		return e.msgFmt
+	}
+	return e.msg
+}
+
+// Wrapf wraps err in Error instance and prefixes its message with the
+// message formatted according to a format specifier. It behaves the same
+// way as WrapMsg. It returns nil if err is nil or typed nil (nil pointer).
+//
+// Arguments are handled in the same manner as in fmt.Sprintf, the %w verb
+// is not supported.
+func Wrapf(err error, format string, args ...interface{}) *Error {
+	if isNil(err) {
+		return nil
+	}
+	return wrapMsg(err, fmt.Sprintf(format, args...), format)
+}
+
+// WrapCodef behaves the same way as Wrapf and additionally sets the error
+// code the same way as WrapMsg does. It returns nil if err is nil or typed
+// nil (nil pointer).
+func WrapCodef(err error, code, format string, args ...interface{}) *Error {
+	if isNil(err) {
+		return nil
+	}
+	return wrapMsg(err, fmt.Sprintf(format, args...), format, code)
+}
+
 // Error represents an error with metadata key value pairs.
 type Error struct {
 	// Wrapped error.
@@ -54,17 +144,48 @@ type Error struct {
 	// Error code.
 	code string
 
+	// Codes previously assigned to the error, the original code first.
+	hist []string
+
+	// Message prefix.
+	msg string
+
+	// Message prefix before it was formatted (see Wrapf), empty when it's
+	// the same as msg.
+	msgFmt string
+
+	// Is the wrapped error message a template (see New and Imm).
+	tpl bool
+
+	// Error severity.
+	sev Severity
+
+	// Error kind.
+	kind Kind
+
+	// Public message.
+	pub string
+
+	// Metadata keys which may be made public.
+	pubKeys []string
+
 	// Is error immutable.
 	// The immutable error instance is never being changed.
 	imm bool
 
+	// Is error a mutable copy of the wrapped immutable instance (see mutable).
+	cp bool
+
 	// Key value metadata associated with the error.
 	meta map[string]interface{}
+
+	// Metadata keys in the insertion order.
+	keys []string
 }
 
 // New is a constructor returning new Error instance.
 func New(msg string, code ...string) *Error {
-	return base(errors.New(msg), false, code...)
+	return tpl(base(errors.New(msg), false, code...))
 }
 
 // Newf is a constructor returning new Error instance.
@@ -81,7 +202,13 @@ func Newf(msg string, args ...interface{}) *Error {
 // Error code is optional, if more than one code is provided the first
 // one will be used.
 func Imm(msg string, code ...string) *Error {
-	return base(errors.New(msg), true, code...)
+	return tpl(base(errors.New(msg), true, code...))
+}
+
+// tpl marks the message of e as a template and returns e.
+func tpl(e *Error) *Error {
+	e.tpl = true
+	return e
 }
 
 // base is a base constructor for Error.
@@ -94,28 +221,120 @@ func base(err error, imm bool, code ...string) *Error {
 	}
 }
 
-// Error implements error interface and returns error message and key value
-// pairs associated with it separated by MsgSep.
-func (e *Error) Error() string { return e.error.Error() }
+// Error implements error interface and returns error message. When the error
+// has a message prefix the message is in form "prefix: cause". The "{key}"
+// placeholders in messages given to New and Imm are replaced with metadata
+// values, see MsgTemplate for the message before rendering. Messages of
+// other errors, formatted messages and message prefixes are never rendered.
+func (e *Error) Error() string { return e.message(e) }
+
+// CauseMsg returns error message without the message prefix.
+func (e *Error) CauseMsg() string { return e.cause(e) }
+
+// Format implements fmt.Formatter interface. The %+v verb prints the error
+// message followed by the error code and metadata key value pairs in the
+// order set with SetMetaOrder. Sensitive values are masked and the limits
+// set with SetLimits are applied. Other verbs, flags and width are applied
+// to the error message the same way they are applied to strings.
+func (e *Error) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('+') {
+		msg, size := outMsg(e.Error())
+		_, _ = io.WriteString(s, msg)
+		if e.code != "" {
+			_, _ = fmt.Fprintf(s, " [%s]", e.code)
+		}
+		for _, f := range outFields(e.metaKeys(), e.meta, size) {
+			_, _ = fmt.Fprintf(s, " %s=%v", f.key, f.val)
+		}
+		return
+	}
+	_, _ = fmt.Fprintf(s, fmt.FormatString(s, verb), e.Error())
+}
 
 // ErrCode returns error code.
 func (e *Error) ErrCode() string { return e.code }
 
-// setCode sets error code to the error.
+// setCode sets error code to the error. The replaced code is kept in the
+// code history.
 func (e *Error) setCode(c string) *Error {
 	if e.imm {
-		return base(e, false, c).SetErrMetadata(e.GetMetadata())
+		ne := e.mutable()
+		ne.recode(c)
+		return ne
 	}
-	e.code = c
+	e.recode(c)
 	return e
 }
 
+// recode replaces the error code with c and records the current code in the
+// code history.
+func (e *Error) recode(c string) {
+	if e.code != "" && e.code != c {
+		e.hist = append(e.hist, e.code)
+	}
+	e.code = c
+}
+
+// Clone returns a copy of the error. The metadata is deep copied, the wrapped
+// error (cause) is shared between the original and the copy.
+func (e *Error) Clone() *Error {
+	ne := *e
+	ne.hist = slices.Clone(e.hist)
+	ne.meta = make(map[string]interface{}, len(e.meta))
+	ne.keys = nil
+	for _, key := range e.keys {
+		ne.set(key, copyValue(e.meta[key]))
+	}
+	return &ne
+}
+
+// Without removes keys from the error metadata. When the error is immutable
+// the keys are removed from the metadata of its mutable copy.
+func (e *Error) Without(keys ...string) *Error {
+	ne := e.mutable()
+	for _, key := range keys {
+		ne.del(key)
+	}
+	return ne
+}
+
+// KeepOnly removes all but the given keys from the error metadata. When
+// the error is immutable the keys are removed from the metadata of its
+// mutable copy.
+func (e *Error) KeepOnly(keys ...string) *Error {
+	ne := e.mutable()
+	for _, key := range slices.Clone(ne.keys) {
+		if !slices.Contains(keys, key) {
+			ne.del(key)
+		}
+	}
+	return ne
+}
+
+// Rename renames the metadata key old to new. The renamed key keeps its
+// position in the insertion order, if the key new already exists it's
+// overwritten. When the error is immutable the key is renamed in the
+// metadata of its mutable copy.
+func (e *Error) Rename(old, new string) *Error {
+	ne := e.mutable()
+	val, ok := ne.meta[old]
+	if !ok || old == new {
+		return ne
+	}
+	ne.del(new)
+	delete(ne.meta, old)
+	ne.meta[new] = val
+	ne.keys[slices.Index(ne.keys, old)] = new
+	return ne
+}
+
 // Str adds the key with string val to the error.
 func (e *Error) Str(key string, s string) *Error { return e.with(key, s) }
 
 // StrAppend appends the string s (prefixed with semicolon) to the string
 // represented by key k. The key will be added if it doesn't exist. If the
 // key already exists and is not a string the old key will be overwritten.
+// Use AppendStr to collect strings in a list.
 func (e *Error) StrAppend(key string, s string) *Error {
 	if si, ok := e.meta[key]; ok {
 		if ss, ok := si.(string); ok {
@@ -140,97 +359,143 @@ func (e *Error) Time(key string, t time.Time) *Error { return e.with(key, t) }
 // Bool adds the key with val as a boolean to the error.
 func (e *Error) Bool(key string, b bool) *Error { return e.with(key, b) }
 
+// Duration adds the key with val as a time.Duration to the error.
+func (e *Error) Duration(key string, d time.Duration) *Error {
+	return e.with(key, d)
+}
+
 // SetErrMetadata sets error metadata. The returned instance might be
 // different from the one this method is called if the error is immutable.
+// Existing keys are overwritten, use MergeMeta for other strategies.
 func (e *Error) SetErrMetadata(src map[string]interface{}) *Error {
-	ee := e
-	for k, v := range src {
-		ee = ee.with(k, v)
-	}
-	return ee
+	ne, _ := e.MergeMeta(src)
+	return ne
 }
 
 // SetMetadataFrom is a convenience method setting metadata from an instance
 // which implements MetadataGetter.
 func (e *Error) SetMetadataFrom(src MetadataGetter) *Error {
-	return e.SetErrMetadata(src.GetMetadata())
+	ne, _ := e.MergeFrom(src)
+	return ne
 }
 
 // GetMetadata returns error metadata. The returned metadata map should be
-// considered read-only.
+// considered read-only. Sensitive values are wrapped, see Reveal.
 func (e *Error) GetMetadata() map[string]interface{} { return e.meta }
 
 // MetaAll returns error metadata. The returned metadata map should be
-// considered read-only.
+// considered read-only. Sensitive values are wrapped, see Reveal.
 func (e *Error) MetaAll() map[string]any { return e.meta }
 
-// with adds context to the error.
-func (e *Error) with(key string, v interface{}) *Error {
-	// Handle immutable error.
-	if e.imm {
-		ne := base(e, false, e.code)
-		ne.meta[key] = v
-		return ne
+// Fields returns an iterator over error metadata key value pairs. The keys
+// are iterated in the order set with SetMetaOrder. Sensitive values are
+// wrapped, see Reveal.
+func (e *Error) Fields() iter.Seq2[string, any] {
+	return func(yield func(string, any) bool) {
+		for _, key := range e.metaKeys() {
+			if !yield(key, e.meta[key]) {
+				return
+			}
+		}
 	}
+}
 
-	e.meta[key] = v
-	return e
+// with adds context to the error.
+// When the error is immutable the context is added to its mutable copy.
+func (e *Error) with(key string, v interface{}) *Error {
+	ne := e.mutable()
+	ne.set(key, v)
+	return ne
 }
 
 // Unwrap unwraps original error.
 func (e *Error) Unwrap() error { return e.error }
 
+// MarshalJSON implements json.Marshaler interface. The metadata keys are
+// serialized in the order set with SetMetaOrder. The fingerprint is included
+// when SetFingerprintJSON is set.
 func (e *Error) MarshalJSON() ([]byte, error) {
-	m := map[string]interface{}{
-		"error": e.Error(),
-		"code":  e.code,
+	buf := &bytes.Buffer{}
+	buf.WriteString(`{"error":`)
+	msg, size := outMsg(e.Error())
+	if err := marshalValue(buf, msg); err != nil {
+		return nil, err
+	}
+	buf.WriteString(`,"code":`)
+	if err := marshalValue(buf, e.code); err != nil {
+		return nil, err
+	}
+	if len(e.hist) > 0 {
+		buf.WriteString(`,"code_history":`)
+		if err := marshalValue(buf, e.CodeHistory()); err != nil {
+			return nil, err
+		}
+	}
+	if fingerprintJSON.Load() {
+		buf.WriteString(`,"fingerprint":`)
+		if err := marshalValue(buf, Fingerprint(e)); err != nil {
+			return nil, err
+		}
+	}
+	if sev := GetSeverity(e); sev != SeverityDefault {
+		buf.WriteString(`,"severity":`)
+		if err := marshalValue(buf, sev.String()); err != nil {
+			return nil, err
+		}
 	}
 	if len(e.meta) > 0 {
-		m["meta"] = e.meta
+		buf.WriteString(`,"meta":`)
+		if err := marshalMeta(buf, e.metaKeys(), e.meta, size); err != nil {
+			return nil, err
+		}
 	}
-	return json.Marshal(m)
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
 }
 
 // UnmarshalJSON unmarshal error's JSON representation.
 // Notes:
 //   - all metadata numeric values will be unmarshalled as float64
+//   - the metadata insertion order is the order of keys in the JSON object
 func (e *Error) UnmarshalJSON(data []byte) error {
-	m := make(map[string]interface{}, 3)
+	m := make(map[string]json.RawMessage, 3)
 
 	if err := json.Unmarshal(data, &m); err != nil {
 		return err
 	}
 
-	msgI, _ := m["error"]
-	msg, _ := msgI.(string)
+	var msg string
+	_ = json.Unmarshal(m["error"], &msg)
 	if msg == "" {
 		return ErrInvJSON
 	}
 
-	codeI, _ := m["code"]
-	code, _ := codeI.(string)
+	var code string
+	_ = json.Unmarshal(m["code"], &code)
 
-	metaI, _ := m["meta"]
-	var meta map[string]interface{}
-	if metaI != nil {
-		meta, _ = metaI.(map[string]interface{})
+	var hist []string
+	_ = json.Unmarshal(m["code_history"], &hist)
+	if n := len(hist); n > 0 && hist[n-1] == code {
+		hist = hist[:n-1]
 	}
-	if meta == nil {
-		meta = make(map[string]interface{})
+
+	var sev string
+	_ = json.Unmarshal(m["severity"], &sev)
+
+	keys, meta, err := unmarshalMeta(m["meta"])
+	if err != nil {
+		return err
 	}
 
 	e.error = errors.New(msg)
 	e.code = code
+	e.hist = hist
+	e.sev = ParseSeverity(sev)
 	e.meta = meta
+	e.keys = keys
 	return nil
 }
 
-// isNil returns true if v is nil or v is nil interface.
-// func isNil(v interface{}) bool {
-//	defer func() { recover() }()
-//	return v == nil || reflect.ValueOf(v).IsNil()
-// }
-
 // firstCode returns first code from the slice.
 func fistCode(code ...string) string {
 	if len(code) > 0 {
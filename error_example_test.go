@@ -1,6 +1,7 @@
 package zrr_test
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"time"
@@ -119,3 +120,75 @@ func ExampleError_Error() {
 
 	// Output: message
 }
+
+func ExampleError_Fields() {
+	err := zrr.New("message", "ECode").
+		Str("str", "string").
+		Int("int", 5).
+		Bool("bool", true)
+
+	for key, val := range err.Fields() {
+		fmt.Printf("%s = %v\n", key, val)
+	}
+
+	// Output:
+	// str = string
+	// int = 5
+	// bool = true
+}
+
+func ExampleAllFields() {
+	err := zrr.New("message", "ECode").Int("retry", 5)
+	err = zrr.Wrap(fmt.Errorf("wrapped: %w", err)).Str("path", "/path")
+
+	for key, val := range zrr.AllFields(err) {
+		fmt.Printf("%s = %v\n", key, val)
+	}
+
+	// Output:
+	// path = /path
+	// retry = 5
+}
+
+func ExampleChain() {
+	err0 := zrr.New("first", "ECode0")
+	err1 := zrr.New("second", "ECode1")
+	err := zrr.Wrap(errors.Join(err0, err1), "ECode")
+
+	for e := range zrr.Chain(err) {
+		fmt.Println(e.ErrCode())
+	}
+
+	// Output:
+	// ECode
+	// ECode0
+	// ECode1
+}
+
+func ExampleWrapMsg() {
+	err := errors.New("file not found")
+
+	e1 := zrr.WrapMsg(err, "loading config", "ECConfig").Str("path", "/etc/app.conf")
+
+	fmt.Println(e1)
+	fmt.Println(e1.CauseMsg())
+	fmt.Println(errors.Is(e1, err))
+	fmt.Println(zrr.GetStr(e1, "path"))
+
+	// Output:
+	// loading config: file not found
+	// file not found
+	// true
+	// /etc/app.conf true
+}
+
+func ExampleWrapCtx() {
+	ctx := zrr.WithMeta(context.Background(), "request_id", "r-123")
+
+	err := zrr.WrapCtx(ctx, errors.New("std error"), "ECode")
+
+	fmt.Println(zrr.GetStr(err, "request_id"))
+
+	// Output:
+	// r-123 true
+}
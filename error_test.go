@@ -3,6 +3,7 @@ package zrr
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
@@ -79,6 +80,191 @@ func Test_Error_ErrCode(t *testing.T) {
 	assert.Equal(t, "ECode", GetCode(err))
 }
 
+func Test_Error_Clone(t *testing.T) {
+	t.Run("mutable", func(t *testing.T) {
+		// --- Given ---
+		list := []string{"a", "b"}
+		err := WrapMsg(errors.New("em0"), "prefix", "ECode").
+			Str("key0", "val0").
+			with("key1", list)
+
+		// --- When ---
+		got := err.Clone()
+
+		// --- Then ---
+		assert.NotSame(t, err, got)
+		assert.Same(t, err.Unwrap(), got.Unwrap())
+		assert.False(t, got.imm)
+		assert.Equal(t, "prefix: em0", got.Error())
+		assert.Equal(t, "ECode", got.ErrCode())
+		assert.Equal(t, err.GetMetadata(), got.GetMetadata())
+		assert.Equal(t, []string{"key0", "key1"}, got.keys)
+
+		got.Str("key2", "val2")
+		got.GetMetadata()["key1"].([]string)[0] = "x"
+		assert.False(t, HasKey(err, "key2"))
+		assert.Equal(t, []string{"a", "b"}, list)
+	})
+
+	t.Run("immutable", func(t *testing.T) {
+		// --- Given ---
+		err := Imm("em0", "ECode")
+
+		// --- When ---
+		got := err.Clone()
+
+		// --- Then ---
+		assert.NotSame(t, err, got)
+		assert.True(t, got.imm)
+		assert.Equal(t, "em0", got.Error())
+		assert.Equal(t, "ECode", got.ErrCode())
+	})
+}
+
+func Test_Error_Without(t *testing.T) {
+	t.Run("mutable", func(t *testing.T) {
+		// --- Given ---
+		err := New("em0").Str("key0", "val0").Int("key1", 1).Int("key2", 2)
+
+		// --- When ---
+		got := err.Without("key0", "key2", "key3")
+
+		// --- Then ---
+		assert.Same(t, err, got)
+		assert.Equal(t, map[string]interface{}{"key1": 1}, got.GetMetadata())
+		assert.Equal(t, []string{"key1"}, got.keys)
+	})
+
+	t.Run("immutable", func(t *testing.T) {
+		// --- Given ---
+		err := Imm("em0", "ECode")
+		err.set("key0", "val0")
+		err.set("key1", 1)
+
+		// --- When ---
+		got := err.Without("key0")
+
+		// --- Then ---
+		assert.NotSame(t, err, got)
+		assert.False(t, got.imm)
+		assert.Equal(t, "ECode", got.ErrCode())
+		assert.Equal(t, map[string]interface{}{"key1": 1}, got.GetMetadata())
+		assert.Len(t, 2, err.GetMetadata())
+		assert.ErrorIs(t, err, got)
+	})
+}
+
+func Test_Error_KeepOnly(t *testing.T) {
+	t.Run("mutable", func(t *testing.T) {
+		// --- Given ---
+		err := New("em0").Str("key0", "val0").Int("key1", 1).Int("key2", 2)
+
+		// --- When ---
+		got := err.KeepOnly("key2", "key0", "key3")
+
+		// --- Then ---
+		assert.Same(t, err, got)
+		exp := map[string]interface{}{"key0": "val0", "key2": 2}
+		assert.Equal(t, exp, got.GetMetadata())
+		assert.Equal(t, []string{"key0", "key2"}, got.keys)
+	})
+
+	t.Run("no keys", func(t *testing.T) {
+		// --- Given ---
+		err := New("em0").Str("key0", "val0").Int("key1", 1)
+
+		// --- When ---
+		got := err.KeepOnly()
+
+		// --- Then ---
+		assert.Len(t, 0, got.GetMetadata())
+		assert.Len(t, 0, got.keys)
+	})
+
+	t.Run("immutable", func(t *testing.T) {
+		// --- Given ---
+		err := Imm("em0", "ECode")
+		err.set("key0", "val0")
+		err.set("key1", 1)
+
+		// --- When ---
+		got := err.KeepOnly("key1")
+
+		// --- Then ---
+		assert.NotSame(t, err, got)
+		assert.False(t, got.imm)
+		assert.Equal(t, map[string]interface{}{"key1": 1}, got.GetMetadata())
+		assert.Len(t, 2, err.GetMetadata())
+	})
+}
+
+func Test_Error_Rename(t *testing.T) {
+	t.Run("keeps position", func(t *testing.T) {
+		// --- Given ---
+		err := New("em0").Str("key0", "val0").Int("key1", 1).Int("key2", 2)
+
+		// --- When ---
+		got := err.Rename("key1", "keyX")
+
+		// --- Then ---
+		assert.Same(t, err, got)
+		exp := map[string]interface{}{"key0": "val0", "keyX": 1, "key2": 2}
+		assert.Equal(t, exp, got.GetMetadata())
+		assert.Equal(t, []string{"key0", "keyX", "key2"}, got.keys)
+	})
+
+	t.Run("overwrites existing", func(t *testing.T) {
+		// --- Given ---
+		err := New("em0").Str("key0", "val0").Int("key1", 1).Int("key2", 2)
+
+		// --- When ---
+		got := err.Rename("key2", "key0")
+
+		// --- Then ---
+		exp := map[string]interface{}{"key0": 2, "key1": 1}
+		assert.Equal(t, exp, got.GetMetadata())
+		assert.Equal(t, []string{"key1", "key0"}, got.keys)
+	})
+
+	t.Run("not existing", func(t *testing.T) {
+		// --- Given ---
+		err := New("em0").Str("key0", "val0")
+
+		// --- When ---
+		got := err.Rename("keyX", "keyY")
+
+		// --- Then ---
+		assert.Equal(t, map[string]interface{}{"key0": "val0"}, got.GetMetadata())
+		assert.Equal(t, []string{"key0"}, got.keys)
+	})
+
+	t.Run("same name", func(t *testing.T) {
+		// --- Given ---
+		err := New("em0").Str("key0", "val0")
+
+		// --- When ---
+		got := err.Rename("key0", "key0")
+
+		// --- Then ---
+		assert.Equal(t, map[string]interface{}{"key0": "val0"}, got.GetMetadata())
+		assert.Equal(t, []string{"key0"}, got.keys)
+	})
+
+	t.Run("immutable", func(t *testing.T) {
+		// --- Given ---
+		err := Imm("em0", "ECode")
+		err.set("key0", "val0")
+
+		// --- When ---
+		got := err.Rename("key0", "keyX")
+
+		// --- Then ---
+		assert.NotSame(t, err, got)
+		assert.Equal(t, map[string]interface{}{"keyX": "val0"}, got.GetMetadata())
+		assert.Equal(t, map[string]interface{}{"key0": "val0"}, err.GetMetadata())
+	})
+}
+
 func Test_Error_Str(t *testing.T) {
 	// --- When ---
 	err := Newf("em0").Str("key0", "val0")
@@ -167,6 +353,15 @@ func Test_Error_Bool(t *testing.T) {
 	assert.Equal(t, map[string]interface{}{"key0": false}, err1.GetMetadata())
 }
 
+func Test_Error_Duration(t *testing.T) {
+	// --- When ---
+	err := Newf("em0").Duration("key0", time.Second)
+
+	// --- Then ---
+	assert.Equal(t, "em0", err.Error())
+	assert.Equal(t, map[string]interface{}{"key0": time.Second}, err.GetMetadata())
+}
+
 func Test_Error_GetMetadata(t *testing.T) {
 	// --- Given ---
 	err0 := Imm("immutable error", "ECode").Int("key", 123)
@@ -209,6 +404,114 @@ func Test_Error_MetaAll(t *testing.T) {
 	assert.Equal(t, exp, got)
 }
 
+func Test_Error_Fields(t *testing.T) {
+	t.Run("insertion order", func(t *testing.T) {
+		// --- Given ---
+		err := New("em0").Str("b", "1").Int("c", 2).Bool("a", true)
+
+		// --- When ---
+		var keys []string
+		var vals []any
+		for key, val := range err.Fields() {
+			keys = append(keys, key)
+			vals = append(vals, val)
+		}
+
+		// --- Then ---
+		assert.Equal(t, []string{"b", "c", "a"}, keys)
+		assert.Equal(t, []any{"1", 2, true}, vals)
+	})
+
+	t.Run("sorted order", func(t *testing.T) {
+		// --- Given ---
+		SetMetaOrder(OrderSorted)
+		t.Cleanup(func() { SetMetaOrder(OrderInsertion) })
+		err := New("em0").Str("b", "1").Int("c", 2).Bool("a", true)
+
+		// --- When ---
+		var keys []string
+		for key := range err.Fields() {
+			keys = append(keys, key)
+		}
+
+		// --- Then ---
+		assert.Equal(t, []string{"a", "b", "c"}, keys)
+	})
+
+	t.Run("break", func(t *testing.T) {
+		// --- Given ---
+		err := New("em0").Str("b", "1").Int("c", 2).Bool("a", true)
+
+		// --- When ---
+		var keys []string
+		for key := range err.Fields() {
+			keys = append(keys, key)
+			break
+		}
+
+		// --- Then ---
+		assert.Equal(t, []string{"b"}, keys)
+	})
+
+	t.Run("immutable copy keeps order", func(t *testing.T) {
+		// --- Given ---
+		err := Imm("em0", "ECode0")
+		err.set("b", "1")
+		err.set("a", "2")
+
+		// --- When ---
+		ne := Wrap(err, "ECode1")
+
+		// --- Then ---
+		var keys []string
+		for key := range ne.Fields() {
+			keys = append(keys, key)
+		}
+		assert.Equal(t, []string{"b", "a"}, keys)
+	})
+}
+
+func Test_Error_Format(t *testing.T) {
+	// --- Given ---
+	err := New("em0", "ECode").Str("b", "1").Int("a", 2)
+
+	tt := []struct {
+		testN string
+
+		format string
+		exp    string
+	}{
+		{"s", "%s", "em0"},
+		{"v", "%v", "em0"},
+		{"q", "%q", `"em0"`},
+		{"+v", "%+v", "em0 [ECode] b=1 a=2"},
+		{"x", "%x", "656d30"},
+		{"X", "%X", "656D30"},
+		{"width", "%-6s|", "em0   |"},
+		{"width right", "%6v|", "   em0|"},
+		{"precision", "%.2s", "em"},
+		{"#q", "%#q", "`em0`"},
+		{"d", "%d", "%!d(string=em0)"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			assert.Equal(t, tc.exp, fmt.Sprintf(tc.format, err))
+		})
+	}
+}
+
+func Test_Error_Format_noCode(t *testing.T) {
+	// --- Given ---
+	err := New("em0").Str("key0", "val0")
+
+	// --- When ---
+	got := fmt.Sprintf("%+v", err)
+
+	// --- Then ---
+	assert.Equal(t, "em0 key0=val0", got)
+}
+
 func Test_Error_Wrap(t *testing.T) {
 	// --- Given ---
 	e := errors.New("std error")
@@ -245,6 +548,18 @@ func Test_Error_Wrap_nil(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func Test_Error_Wrap_typedNil(t *testing.T) {
+	// --- Given ---
+	var ze *Error
+	var ne *nilErr
+
+	// --- Then ---
+	assert.Nil(t, Wrap(ze))
+	assert.Nil(t, Wrap(ne, "ECode"))
+	assert.Nil(t, WrapMsg(ne, "prefix"))
+	assert.Nil(t, Wrapf(ne, "prefix %d", 1))
+}
+
 func Test_Error_Wrap_Error(t *testing.T) {
 	// --- Given ---
 	err0 := New("test msg")
@@ -256,6 +571,208 @@ func Test_Error_Wrap_Error(t *testing.T) {
 	assert.Same(t, err0, err1)
 }
 
+func Test_WrapMsg(t *testing.T) {
+	t.Run("std error", func(t *testing.T) {
+		// --- Given ---
+		e := errors.New("std error")
+
+		// --- When ---
+		err := WrapMsg(e, "loading config")
+
+		// --- Then ---
+		assert.False(t, err.imm)
+		assert.Equal(t, "loading config: std error", err.Error())
+		assert.Equal(t, "std error", err.CauseMsg())
+		assert.Equal(t, "", err.ErrCode())
+		assert.Same(t, e, err.Unwrap())
+		assert.ErrorIs(t, e, err)
+	})
+
+	t.Run("std error with code", func(t *testing.T) {
+		// --- Given ---
+		e := errors.New("std error")
+
+		// --- When ---
+		err := WrapMsg(e, "loading config", "ECode0", "ECode1")
+
+		// --- Then ---
+		assert.Equal(t, "loading config: std error", err.Error())
+		assert.Equal(t, "ECode0", err.ErrCode())
+	})
+
+	t.Run("mutable error", func(t *testing.T) {
+		// --- Given ---
+		e := New("em0", "ECode0").Str("key0", "val0")
+
+		// --- When ---
+		err := WrapMsg(e, "loading config")
+
+		// --- Then ---
+		assert.Same(t, e, err)
+		assert.Equal(t, "loading config: em0", err.Error())
+		assert.Equal(t, "em0", err.CauseMsg())
+		assert.Equal(t, "ECode0", err.ErrCode())
+		assert.True(t, HasKey(err, "key0"))
+	})
+
+	t.Run("mutable error with code", func(t *testing.T) {
+		// --- Given ---
+		e := New("em0", "ECode0")
+
+		// --- When ---
+		err := WrapMsg(e, "loading config", "ECode1")
+
+		// --- Then ---
+		assert.Same(t, e, err)
+		assert.Equal(t, "ECode1", err.ErrCode())
+	})
+
+	t.Run("stacked prefixes", func(t *testing.T) {
+		// --- Given ---
+		e := WrapMsg(errors.New("std error"), "reading file")
+
+		// --- When ---
+		err := WrapMsg(e, "loading config")
+
+		// --- Then ---
+		assert.Equal(t, "loading config: reading file: std error", err.Error())
+		assert.Equal(t, "std error", err.CauseMsg())
+	})
+
+	t.Run("immutable error", func(t *testing.T) {
+		// --- Given ---
+		e := Imm("em0", "ECode0")
+
+		// --- When ---
+		err := WrapMsg(e, "loading config")
+
+		// --- Then ---
+		assert.NotSame(t, e, err)
+		assert.False(t, err.imm)
+		assert.Equal(t, "loading config: em0", err.Error())
+		assert.Equal(t, "em0", err.CauseMsg())
+		assert.Equal(t, "ECode0", err.ErrCode())
+		assert.Equal(t, "em0", e.Error())
+		assert.ErrorIs(t, e, err)
+	})
+
+	t.Run("immutable error with code", func(t *testing.T) {
+		// --- Given ---
+		e := Imm("em0", "ECode0")
+
+		// --- When ---
+		err := WrapMsg(e, "loading config", "ECode1")
+
+		// --- Then ---
+		assert.Equal(t, "ECode1", err.ErrCode())
+		assert.Equal(t, "ECode0", e.ErrCode())
+	})
+
+	t.Run("with metadata", func(t *testing.T) {
+		// --- Given ---
+		e := errors.New("std error")
+		meta := map[string]interface{}{"path": "/etc/app.conf"}
+
+		// --- When ---
+		err := WrapMsg(e, "loading config", "ECode").SetErrMetadata(meta)
+
+		// --- Then ---
+		assert.Equal(t, meta, err.GetMetadata())
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		// --- When ---
+		err := WrapMsg(nil, "loading config")
+
+		// --- Then ---
+		assert.Nil(t, err)
+	})
+}
+
+func Test_Wrapf(t *testing.T) {
+	t.Run("std error", func(t *testing.T) {
+		// --- Given ---
+		e := errors.New("std error")
+
+		// --- When ---
+		err := Wrapf(e, "loading %s", "config")
+
+		// --- Then ---
+		assert.Equal(t, "loading config: std error", err.Error())
+		assert.Equal(t, "std error", err.CauseMsg())
+		assert.ErrorIs(t, e, err)
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		// --- When ---
+		err := Wrapf(nil, "loading %s", "config")
+
+		// --- Then ---
+		assert.Nil(t, err)
+	})
+}
+
+func Test_WrapCodef(t *testing.T) {
+	t.Run("std error", func(t *testing.T) {
+		// --- Given ---
+		e := errors.New("std error")
+
+		// --- When ---
+		err := WrapCodef(e, "ECode", "loading %s", "config")
+
+		// --- Then ---
+		assert.Equal(t, "loading config: std error", err.Error())
+		assert.Equal(t, "ECode", err.ErrCode())
+		assert.ErrorIs(t, e, err)
+	})
+
+	t.Run("mutable error", func(t *testing.T) {
+		// --- Given ---
+		e := New("em0", "ECode0")
+
+		// --- When ---
+		err := WrapCodef(e, "ECode1", "loading %s", "config")
+
+		// --- Then ---
+		assert.Same(t, e, err)
+		assert.Equal(t, "loading config: em0", err.Error())
+		assert.Equal(t, "ECode1", err.ErrCode())
+	})
+
+	t.Run("immutable error", func(t *testing.T) {
+		// --- Given ---
+		e := Imm("em0", "ECode0")
+
+		// --- When ---
+		err := WrapCodef(e, "ECode1", "loading %s", "config")
+
+		// --- Then ---
+		assert.NotSame(t, e, err)
+		assert.Equal(t, "loading config: em0", err.Error())
+		assert.Equal(t, "ECode1", err.ErrCode())
+		assert.Equal(t, "ECode0", e.ErrCode())
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		// --- When ---
+		err := WrapCodef(nil, "ECode", "loading %s", "config")
+
+		// --- Then ---
+		assert.Nil(t, err)
+	})
+}
+
+func Test_Error_CauseMsg(t *testing.T) {
+	// --- Given ---
+	err := New("em0")
+
+	// --- When ---
+	got := err.CauseMsg()
+
+	// --- Then ---
+	assert.Equal(t, "em0", got)
+}
+
 func Test_Error_Unwrap(t *testing.T) {
 	// --- Given ---
 	err0 := errors.New("std error")
@@ -354,6 +871,59 @@ func Test_Error_MarshalJSON(t *testing.T) {
 		exp := `{"error":"test msg", "code":"ECTest", "meta": {"key": "value"}}`
 		assert.JSON(t, exp, string(data))
 	})
+
+	t.Run("meta in insertion order", func(t *testing.T) {
+		// --- Given ---
+		e := New("test msg", "ECTest").Str("b", "1").Int("c", 2).Str("a", "3")
+
+		// --- When ---
+		data, err := json.Marshal(e)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		exp := `{"error":"test msg","code":"ECTest","meta":{"b":"1","c":2,"a":"3"}}`
+		assert.Equal(t, exp, string(data))
+	})
+
+	t.Run("meta in sorted order", func(t *testing.T) {
+		// --- Given ---
+		SetMetaOrder(OrderSorted)
+		t.Cleanup(func() { SetMetaOrder(OrderInsertion) })
+		e := New("test msg", "ECTest").Str("b", "1").Int("c", 2).Str("a", "3")
+
+		// --- When ---
+		data, err := json.Marshal(e)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		exp := `{"error":"test msg","code":"ECTest","meta":{"a":"3","b":"1","c":2}}`
+		assert.Equal(t, exp, string(data))
+	})
+
+	t.Run("with message prefix", func(t *testing.T) {
+		// --- Given ---
+		e := WrapMsg(errors.New("test msg"), "prefix", "ECTest")
+
+		// --- When ---
+		data, err := json.Marshal(e)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		exp := `{"error":"prefix: test msg", "code":"ECTest"}`
+		assert.JSON(t, exp, string(data))
+	})
+
+	t.Run("meta value error", func(t *testing.T) {
+		// --- Given ---
+		e := New("test msg").with("key", make(chan int))
+
+		// --- When ---
+		data, err := json.Marshal(e)
+
+		// --- Then ---
+		assert.Error(t, err)
+		assert.Nil(t, data)
+	})
 }
 
 func Test_Error_UnmarshalJSON(t *testing.T) {
@@ -412,6 +982,35 @@ func Test_Error_UnmarshalJSON(t *testing.T) {
 		assert.HasKey(t, "key", e.meta)
 		assert.Equal(t, float64(123), e.meta["key"])
 		assert.Equal(t, "2022-01-18T13:57:00Z", e.meta["tim"])
+		assert.Equal(t, []string{"key", "tim"}, e.keys)
+	})
+
+	t.Run("meta keeps JSON order", func(t *testing.T) {
+		// --- Given ---
+		data := []byte(`{"error":"test msg","meta":{"b":1,"c":2,"a":3}}`)
+
+		// --- When ---
+		var e *Error
+		err := json.Unmarshal(data, &e)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"b", "c", "a"}, e.keys)
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		// --- Given ---
+		exp := `{"error":"test msg","code":"ECode","meta":{"z":"1","a":2}}`
+
+		// --- When ---
+		var e *Error
+		err := json.Unmarshal([]byte(exp), &e)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		data, err := json.Marshal(e)
+		assert.NoError(t, err)
+		assert.Equal(t, exp, string(data))
 	})
 
 	t.Run("without error key", func(t *testing.T) {
@@ -459,3 +1058,14 @@ func Test_Error_SetMetadataFrom(t *testing.T) {
 	}
 	assert.Equal(t, exp, ne.GetMetadata())
 }
+
+func Test_Error_SetErrMetadata_sortedSource(t *testing.T) {
+	// --- Given ---
+	src := map[string]interface{}{"k2": 2, "k0": 0, "k1": 1}
+
+	// --- When ---
+	err := New("message").Str("k3", "3").SetErrMetadata(src)
+
+	// --- Then ---
+	assert.Equal(t, []string{"k3", "k0", "k1", "k2"}, err.keys)
+}
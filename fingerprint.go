@@ -0,0 +1,100 @@
+package zrr
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"slices"
+	"strings"
+	"sync/atomic"
+)
+
+// fingerprintKeys is the package wide list of metadata keys used to compute
+// fingerprints.
+var fingerprintKeys atomic.Pointer[[]string]
+
+// fingerprintJSON when true, MarshalJSON includes the error fingerprint.
+var fingerprintJSON atomic.Bool
+
+// SetFingerprintKeys sets metadata keys which values are used to compute
+// fingerprints. By default, no metadata values are used.
+func SetFingerprintKeys(keys ...string) {
+	keys = slices.Clone(keys)
+	fingerprintKeys.Store(&keys)
+}
+
+// SetFingerprintJSON sets whether MarshalJSON includes the error fingerprint
+// under the "fingerprint" key. By default, the fingerprint is not included.
+func SetFingerprintJSON(include bool) { fingerprintJSON.Store(include) }
+
+// Fingerprint returns a stable fingerprint of err for grouping identical
+// failures. It's computed from the codes, message prefixes (format strings
+// of prefixes given to Wrapf and WrapCodef) and message templates given to
+// New and Imm of Error instances in the err chain, function names of the
+// stack trace recorded under KeyStack and values of keys set with
+// SetFingerprintKeys. Other metadata values are ignored, so are the
+// messages of wrapped errors, unless the wrapping instance has no code. When
+// there are no Error instances in the chain the error message is used. It
+// returns empty string if err is nil.
+func Fingerprint(err error) string {
+	if isNil(err) {
+		return ""
+	}
+	h := sha256.New()
+	var found bool
+	var code string
+	for e := range Chain(err) {
+		found = true
+		// Consecutive equal codes are written once, so mutable copies of
+		// immutable errors have the same fingerprint as the originals.
+		if e.code != "" && e.code != code {
+			writePart(h, "c:"+e.code)
+		}
+		code = e.code
+		if e.msg != "" {
+			writePart(h, "m:"+e.msgFormat())
+		}
+		// Messages of errors not created by New or Imm may have volatile
+		// parameters, they are used only when the instance has no code.
+		if isLeaf(e.error) && (e.tpl || e.code == "") {
+			writePart(h, "m:"+e.error.Error())
+		}
+		if frames, ok := e.meta[KeyStack].([]string); ok {
+			for _, frame := range frames {
+				fn, _, _ := strings.Cut(frame, " ")
+				writePart(h, "f:"+fn)
+			}
+		}
+	}
+	if !found {
+		writePart(h, "m:"+err.Error())
+	}
+	if keys := fingerprintKeys.Load(); keys != nil && len(*keys) > 0 {
+		for key, val := range AllFields(err) {
+			if slices.Contains(*keys, key) {
+				writePart(h, "k:"+key)
+				writePart(h, "v:"+fmt.Sprint(Redact(key, val)))
+			}
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+// writePart writes fingerprint part to h.
+func writePart(h hash.Hash, part string) {
+	_, _ = h.Write([]byte(part))
+	_, _ = h.Write([]byte{0})
+}
+
+// isLeaf returns true if err does not wrap other errors.
+func isLeaf(err error) bool {
+	if errors.Unwrap(err) != nil {
+		return false
+	}
+	if u, ok := err.(interface{ Unwrap() []error }); ok {
+		return len(u.Unwrap()) == 0
+	}
+	return true
+}
@@ -0,0 +1,221 @@
+package zrr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+)
+
+// setFingerprintKeys sets fingerprint keys for the duration of the test.
+func setFingerprintKeys(t *testing.T, keys ...string) {
+	t.Helper()
+	saved := fingerprintKeys.Load()
+	t.Cleanup(func() { fingerprintKeys.Store(saved) })
+	SetFingerprintKeys(keys...)
+}
+
+// setFingerprintJSON sets fingerprint JSON inclusion for the duration of
+// the test.
+func setFingerprintJSON(t *testing.T, include bool) {
+	t.Helper()
+	saved := fingerprintJSON.Load()
+	t.Cleanup(func() { fingerprintJSON.Store(saved) })
+	SetFingerprintJSON(include)
+}
+
+func Test_Fingerprint(t *testing.T) {
+	t.Run("format", func(t *testing.T) {
+		// --- When ---
+		have := Fingerprint(New("em0", "ECode"))
+
+		// --- Then ---
+		assert.Len(t, 16, have)
+	})
+
+	t.Run("stable", func(t *testing.T) {
+		// --- Given ---
+		e0 := New("em0", "ECode")
+		e1 := New("em0", "ECode")
+
+		// --- Then ---
+		assert.Equal(t, Fingerprint(e0), Fingerprint(e1))
+	})
+
+	t.Run("metadata values are ignored", func(t *testing.T) {
+		// --- Given ---
+		sentinel := Imm("user {user_id} not found", "ECUserNotFound")
+		e0 := sentinel.Int("user_id", 1)
+		e1 := sentinel.Int("user_id", 2).Str("host", "db1")
+
+		// --- Then ---
+		assert.NotEqual(t, e0.Error(), e1.Error())
+		assert.Equal(t, Fingerprint(sentinel), Fingerprint(e0))
+		assert.Equal(t, Fingerprint(e0), Fingerprint(e1))
+	})
+
+	t.Run("wrapping message parameters are ignored", func(t *testing.T) {
+		// --- Given ---
+		sentinel := Imm("not found", "ECNotFound")
+		e0 := Wrap(fmt.Errorf("get %d: %w", 1, sentinel))
+		e1 := Wrap(fmt.Errorf("get %d: %w", 2, sentinel))
+
+		// --- Then ---
+		assert.Equal(t, Fingerprint(e0), Fingerprint(e1))
+	})
+
+	t.Run("wrapped error message is ignored", func(t *testing.T) {
+		// --- Given ---
+		e0 := Wrap(errors.New("dial tcp 10.0.0.1:5432: refused"), "EDial")
+		e1 := Wrap(errors.New("dial tcp 10.0.0.2:5432: refused"), "EDial")
+
+		// --- Then ---
+		assert.Equal(t, Fingerprint(e0), Fingerprint(e1))
+	})
+
+	t.Run("formatted message is ignored", func(t *testing.T) {
+		// --- Given ---
+		e0 := Wrap(Newf("user %d", 1), "ECode")
+		e1 := Wrap(Newf("user %d", 2), "ECode")
+
+		// --- Then ---
+		assert.Equal(t, Fingerprint(e0), Fingerprint(e1))
+	})
+
+	t.Run("formatted prefix is ignored", func(t *testing.T) {
+		// --- Given ---
+		e0 := Wrapf(New("x", "C"), "load %d", 1)
+		e1 := Wrapf(New("x", "C"), "load %d", 2)
+
+		// --- Then ---
+		assert.Equal(t, Fingerprint(e0), Fingerprint(e1))
+		assert.NotEqual(t, Fingerprint(e0), Fingerprint(Wrapf(New("x", "C"), "save %d", 1)))
+	})
+
+	t.Run("formatted prefix with code is ignored", func(t *testing.T) {
+		// --- Given ---
+		e0 := WrapCodef(Imm("x", "C"), "ECode", "load %d", 1)
+		e1 := WrapCodef(Imm("x", "C"), "ECode", "load %d", 2)
+
+		// --- Then ---
+		assert.Equal(t, Fingerprint(e0), Fingerprint(e1))
+	})
+
+	t.Run("stacked formatted prefix is ignored", func(t *testing.T) {
+		// --- Given ---
+		e0 := WrapMsg(Wrapf(New("x", "C"), "load %d", 1), "api")
+		e1 := WrapMsg(Wrapf(New("x", "C"), "load %d", 2), "api")
+
+		// --- Then ---
+		assert.Equal(t, "api: load 1: x", e0.Error())
+		assert.Equal(t, Fingerprint(e0), Fingerprint(e1))
+	})
+
+	t.Run("wrapped error message without code", func(t *testing.T) {
+		// --- Given ---
+		e0 := Wrap(errors.New("em0"))
+		e1 := Wrap(errors.New("em1"))
+
+		// --- Then ---
+		assert.NotEqual(t, Fingerprint(e0), Fingerprint(e1))
+	})
+
+	t.Run("different codes", func(t *testing.T) {
+		// --- Given ---
+		e0 := New("em0", "ECode0")
+		e1 := New("em0", "ECode1")
+
+		// --- Then ---
+		assert.NotEqual(t, Fingerprint(e0), Fingerprint(e1))
+	})
+
+	t.Run("codes along the chain", func(t *testing.T) {
+		// --- Given ---
+		e0 := Wrap(fmt.Errorf("w: %w", New("em0", "ECode0")), "ECOuter")
+		e1 := Wrap(fmt.Errorf("w: %w", New("em0", "ECode1")), "ECOuter")
+
+		// --- Then ---
+		assert.NotEqual(t, Fingerprint(e0), Fingerprint(e1))
+	})
+
+	t.Run("different message templates", func(t *testing.T) {
+		// --- Given ---
+		e0 := New("em0", "ECode")
+		e1 := New("em1", "ECode")
+		e2 := WrapMsg(New("em0", "ECode"), "prefix")
+
+		// --- Then ---
+		assert.NotEqual(t, Fingerprint(e0), Fingerprint(e1))
+		assert.NotEqual(t, Fingerprint(e0), Fingerprint(e2))
+	})
+
+	t.Run("stack function names", func(t *testing.T) {
+		// --- Given ---
+		e0 := New("panic", ECPanic).with(KeyStack, []string{"pkg.a file.go:1"})
+		e1 := New("panic", ECPanic).with(KeyStack, []string{"pkg.a file.go:2"})
+		e2 := New("panic", ECPanic).with(KeyStack, []string{"pkg.b file.go:1"})
+
+		// --- Then ---
+		assert.Equal(t, Fingerprint(e0), Fingerprint(e1))
+		assert.NotEqual(t, Fingerprint(e0), Fingerprint(e2))
+	})
+
+	t.Run("configured keys", func(t *testing.T) {
+		// --- Given ---
+		setFingerprintKeys(t, "table")
+		e0 := New("em0", "ECode").Str("table", "users").Int("id", 1)
+		e1 := New("em0", "ECode").Str("table", "users").Int("id", 2)
+		e2 := New("em0", "ECode").Str("table", "orders").Int("id", 1)
+
+		// --- Then ---
+		assert.Equal(t, Fingerprint(e0), Fingerprint(e1))
+		assert.NotEqual(t, Fingerprint(e0), Fingerprint(e2))
+	})
+
+	t.Run("std error", func(t *testing.T) {
+		// --- Given ---
+		e0 := errors.New("em0")
+
+		// --- Then ---
+		assert.Len(t, 16, Fingerprint(e0))
+		assert.Equal(t, Fingerprint(e0), Fingerprint(errors.New("em0")))
+		assert.NotEqual(t, Fingerprint(e0), Fingerprint(errors.New("em1")))
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		assert.Equal(t, "", Fingerprint(nil))
+	})
+}
+
+func Test_Error_MarshalJSON_fingerprint(t *testing.T) {
+	t.Run("included", func(t *testing.T) {
+		// --- Given ---
+		setFingerprintJSON(t, true)
+		err := New("em0", "ECode")
+
+		// --- When ---
+		data, jErr := json.Marshal(err)
+
+		// --- Then ---
+		assert.NoError(t, jErr)
+		want := fmt.Sprintf(
+			`{"error":"em0","code":"ECode","fingerprint":%q}`,
+			Fingerprint(err),
+		)
+		assert.JSON(t, want, string(data))
+	})
+
+	t.Run("not included by default", func(t *testing.T) {
+		// --- Given ---
+		err := New("em0", "ECode")
+
+		// --- When ---
+		data, jErr := json.Marshal(err)
+
+		// --- Then ---
+		assert.NoError(t, jErr)
+		assert.JSON(t, `{"error":"em0","code":"ECode"}`, string(data))
+	})
+}
@@ -2,13 +2,13 @@ package zrr
 
 import (
 	"errors"
+	"reflect"
 	"time"
 )
 
 // IsImmutable returns true if error err is instance of Error and is immutable.
 func IsImmutable(err error) bool {
-	var e *Error
-	if errors.As(err, &e) && e != nil {
+	if e, ok := asError(err); ok {
 		return e.imm
 	}
 	return false
@@ -24,9 +24,9 @@ func HasKey(err error, key string) bool {
 }
 
 // HasCode returns true if error err is instance of Error and has any of the codes.
+// Use HasCodeHistory to match codes the error had before it was re-coded.
 func HasCode(err error, codes ...string) bool {
-	var e *Error
-	if errors.As(err, &e) && e != nil {
+	if e, ok := asError(err); ok {
 		for _, code := range codes {
 			if code == e.code {
 				return true
@@ -39,8 +39,7 @@ func HasCode(err error, codes ...string) bool {
 // GetCode returns error code if error err is instance of Error.
 // If error code is not set it will return empty string.
 func GetCode(err error) string {
-	var e *Error
-	if errors.As(err, &e) && e != nil {
+	if e, ok := asError(err); ok {
 		return e.code
 	}
 	return ""
@@ -50,9 +49,8 @@ func GetCode(err error) string {
 // exists. If key does not exist, or it's not a string it will return
 // false as the second return value.
 func GetStr(err error, key string) (string, bool) {
-	var e *Error
-	if errors.As(err, &e) && e != nil {
-		if val, ok := e.meta[key]; ok {
+	if e, ok := asError(err); ok {
+		if val, ok := e.get(key); ok {
 			if ret, ok := val.(string); ok {
 				return ret, true
 			}
@@ -65,9 +63,8 @@ func GetStr(err error, key string) (string, bool) {
 // exists. If key does not exist, or it's not an integer it will return
 // false as the second return value.
 func GetInt(err error, key string) (int, bool) {
-	var e *Error
-	if errors.As(err, &e) && e != nil {
-		if val, ok := e.meta[key]; ok {
+	if e, ok := asError(err); ok {
+		if val, ok := e.get(key); ok {
 			if ret, ok := val.(int); ok {
 				return ret, true
 			}
@@ -80,9 +77,8 @@ func GetInt(err error, key string) (int, bool) {
 // exists. If key does not exist, or it's not an int64 it will return
 // false as the second return value.
 func GetInt64(err error, key string) (int64, bool) {
-	var e *Error
-	if errors.As(err, &e) && e != nil {
-		if val, ok := e.meta[key]; ok {
+	if e, ok := asError(err); ok {
+		if val, ok := e.get(key); ok {
 			if ret, ok := val.(int64); ok {
 				return ret, true
 			}
@@ -95,9 +91,8 @@ func GetInt64(err error, key string) (int64, bool) {
 // and key exists. If key does not exist, or it's not a float64 it will return
 // false as the second return value.
 func GetFloat64(err error, key string) (float64, bool) {
-	var e *Error
-	if errors.As(err, &e) && e != nil {
-		if val, ok := e.meta[key]; ok {
+	if e, ok := asError(err); ok {
+		if val, ok := e.get(key); ok {
 			if ret, ok := val.(float64); ok {
 				return ret, true
 			}
@@ -110,9 +105,8 @@ func GetFloat64(err error, key string) (float64, bool) {
 // and key exists. If key does not exist, or it's not a time.Time it will return
 // false as the second return value.
 func GetTime(err error, key string) (time.Time, bool) {
-	var e *Error
-	if errors.As(err, &e) && e != nil {
-		if val, ok := e.meta[key]; ok {
+	if e, ok := asError(err); ok {
+		if val, ok := e.get(key); ok {
 			if ret, ok := val.(time.Time); ok {
 				return ret, true
 			}
@@ -125,9 +119,8 @@ func GetTime(err error, key string) (time.Time, bool) {
 // exists. If key does not exist, or it is not a boolean it will return
 // false as the second return value.
 func GetBool(err error, key string) (bool, bool) {
-	var e *Error
-	if errors.As(err, &e) && e != nil {
-		if val, ok := e.meta[key]; ok {
+	if e, ok := asError(err); ok {
+		if val, ok := e.get(key); ok {
 			if ret, ok := val.(bool); ok {
 				return ret, true
 			}
@@ -135,3 +128,39 @@ func GetBool(err error, key string) (bool, bool) {
 	}
 	return false, false
 }
+
+// GetDuration returns the key as a time.Duration if err is an instance of
+// Error and key exists. If key does not exist, or it is not a time.Duration
+// it will return false as the second return value.
+func GetDuration(err error, key string) (time.Duration, bool) {
+	if e, ok := asError(err); ok {
+		if val, ok := e.get(key); ok {
+			if ret, ok := val.(time.Duration); ok {
+				return ret, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// asError finds the first Error instance in the err chain. It returns false
+// if err is nil, typed nil or there is no Error instance in the chain.
+func asError(err error) (*Error, bool) {
+	if isNil(err) {
+		return nil, false
+	}
+	var e *Error
+	if errors.As(err, &e) && e != nil {
+		return e, true
+	}
+	return nil, false
+}
+
+// isNil returns true if err is nil or is a nil pointer (typed nil).
+func isNil(err error) bool {
+	if err == nil {
+		return true
+	}
+	v := reflect.ValueOf(err)
+	return v.Kind() == reflect.Ptr && v.IsNil()
+}
@@ -263,13 +263,43 @@ func Test_GetBool(t *testing.T) {
 	}
 }
 
+func Test_GetDuration(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		err   error
+		key   string
+		value time.Duration
+		exist bool
+	}{
+		{"1", New("em0"), "key0", 0, false},
+		{"2", New("em0").Duration("key0", 0), "key0", 0, true},
+		{"3", New("em0").Duration("key0", time.Second), "key0", time.Second, true},
+		{"4", New("em0").Int64("key0", 1), "key0", 0, false},
+		{"5", nil, "key0", 0, false},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- When ---
+			value, exist := GetDuration(tc.err, tc.key)
+
+			// --- Then ---
+			assert.Equal(t, tc.exist, exist)
+			assert.Equal(t, tc.value, value)
+		})
+	}
+}
+
 func Test_NilPointerError(t *testing.T) {
 	// --- Given ---
 	var err *Error
 
 	// --- Then ---
+	assert.False(t, IsImmutable(err))
 	assert.False(t, HasKey(err, "key0"))
 	assert.False(t, HasCode(err, "ECode"))
+	assert.Equal(t, "", GetCode(err))
 
 	_, got := GetStr(err, "key0")
 	assert.False(t, got)
@@ -277,6 +307,9 @@ func Test_NilPointerError(t *testing.T) {
 	_, got = GetInt(err, "key0")
 	assert.False(t, got)
 
+	_, got = GetInt64(err, "key0")
+	assert.False(t, got)
+
 	_, got = GetFloat64(err, "key0")
 	assert.False(t, got)
 
@@ -285,4 +318,71 @@ func Test_NilPointerError(t *testing.T) {
 
 	_, got = GetBool(err, "key0")
 	assert.False(t, got)
+
+	_, got = GetDuration(err, "key0")
+	assert.False(t, got)
+}
+
+// nilErr is an error type with pointer receiver panicking when called on
+// nil pointer.
+type nilErr struct{ msg string }
+
+func (e *nilErr) Error() string { return e.msg }
+func (e *nilErr) Unwrap() error { return errors.New(e.msg) }
+
+func Test_TypedNilError(t *testing.T) {
+	// --- Given ---
+	var ne *nilErr
+	var err error = ne
+
+	// --- Then ---
+	assert.False(t, IsImmutable(err))
+	assert.False(t, HasKey(err, "key0"))
+	assert.False(t, HasCode(err, "ECode"))
+	assert.Equal(t, "", GetCode(err))
+
+	_, got := GetStr(err, "key0")
+	assert.False(t, got)
+
+	_, got = GetInt(err, "key0")
+	assert.False(t, got)
+
+	_, got = GetInt64(err, "key0")
+	assert.False(t, got)
+
+	_, got = GetFloat64(err, "key0")
+	assert.False(t, got)
+
+	_, got = GetTime(err, "key0")
+	assert.False(t, got)
+
+	_, got = GetBool(err, "key0")
+	assert.False(t, got)
+
+	_, got = GetDuration(err, "key0")
+	assert.False(t, got)
+}
+
+func Test_isNil(t *testing.T) {
+	var ze *Error
+	var ne *nilErr
+
+	tt := []struct {
+		testN string
+
+		exp bool
+		err error
+	}{
+		{"nil", true, nil},
+		{"nil Error", true, ze},
+		{"typed nil", true, ne},
+		{"Error", false, New("em0")},
+		{"std error", false, errors.New("em0")},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			assert.Equal(t, tc.exp, isNil(tc.err))
+		})
+	}
 }
@@ -0,0 +1,95 @@
+package zrr
+
+import (
+	"iter"
+	"slices"
+)
+
+// Chain returns an iterator over every Error instance in the err chain. The
+// chain is walked depth-first, errors joined with errors.Join (or any other
+// error implementing Unwrap() []error) are visited in order. The walk stops
+// at nil and typed nil (nil pointer) errors.
+func Chain(err error) iter.Seq[*Error] {
+	return func(yield func(*Error) bool) { walk(err, yield) }
+}
+
+// AllFields returns an iterator over metadata key value pairs merged from
+// every Error instance in the err chain. When the same key is set on more
+// than one instance, the value closest to err wins, unless both values are
+// lists, in which case they are concatenated with the deepest instance
+// values first. Keys are iterated in the order they are found when walking
+// the chain or sorted when SetMetaOrder was called with OrderSorted.
+// Sensitive values are wrapped, see Reveal.
+func AllFields(err error) iter.Seq2[string, any] {
+	return func(yield func(string, any) bool) {
+		var keys []string
+		meta := make(map[string]any)
+		for e := range metaLayers(err) {
+			for _, key := range e.keys {
+				val := e.meta[key]
+				cur, ok := meta[key]
+				if !ok {
+					keys = append(keys, key)
+					meta[key] = val
+					continue
+				}
+				if isList(cur) && isList(val) {
+					meta[key] = appendValue(val, cur)
+				}
+			}
+		}
+		if GetMetaOrder() == OrderSorted {
+			slices.Sort(keys)
+		}
+		for _, key := range keys {
+			if !yield(key, meta[key]) {
+				return
+			}
+		}
+	}
+}
+
+// metaLayers returns an iterator over Error instances in the err chain
+// skipping immutable instances wrapped by their mutable copies. Such
+// instances had their metadata copied to the copy (see mutable).
+func metaLayers(err error) iter.Seq[*Error] {
+	return func(yield func(*Error) bool) {
+		var prev *Error
+		for e := range Chain(err) {
+			skip := prev != nil && prev.cp && prev.error == error(e)
+			prev = e
+			if skip {
+				continue
+			}
+			if !yield(e) {
+				return
+			}
+		}
+	}
+}
+
+// walk walks the err chain depth-first calling yield for every Error
+// instance. It returns false when the walk was stopped by yield.
+func walk(err error, yield func(*Error) bool) bool {
+	for !isNil(err) {
+		if e, ok := err.(*Error); ok {
+			if !yield(e) {
+				return false
+			}
+		}
+		switch x := err.(type) {
+		case interface{ Unwrap() []error }:
+			for _, je := range x.Unwrap() {
+				if !walk(je, yield) {
+					return false
+				}
+			}
+			return true
+		case interface{ Unwrap() error }:
+			err = x.Unwrap()
+		default:
+			return true
+		}
+	}
+	return true
+}
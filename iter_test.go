@@ -0,0 +1,219 @@
+package zrr
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+)
+
+func Test_Chain(t *testing.T) {
+	t.Run("single", func(t *testing.T) {
+		// --- Given ---
+		err := New("em0")
+
+		// --- When ---
+		var got []*Error
+		for e := range Chain(err) {
+			got = append(got, e)
+		}
+
+		// --- Then ---
+		assert.Len(t, 1, got)
+		assert.Same(t, err, got[0])
+	})
+
+	t.Run("wrapped", func(t *testing.T) {
+		// --- Given ---
+		err0 := New("em0")
+		err1 := Wrap(fmt.Errorf("wrap: %w", err0))
+
+		// --- When ---
+		var got []*Error
+		for e := range Chain(err1) {
+			got = append(got, e)
+		}
+
+		// --- Then ---
+		assert.Len(t, 2, got)
+		assert.Same(t, err1, got[0])
+		assert.Same(t, err0, got[1])
+	})
+
+	t.Run("immutable clone", func(t *testing.T) {
+		// --- Given ---
+		err0 := Imm("em0")
+		err1 := err0.Str("key0", "val0")
+
+		// --- When ---
+		var got []*Error
+		for e := range Chain(err1) {
+			got = append(got, e)
+		}
+
+		// --- Then ---
+		assert.Len(t, 2, got)
+		assert.Same(t, err1, got[0])
+		assert.Same(t, err0, got[1])
+	})
+
+	t.Run("joined depth first", func(t *testing.T) {
+		// --- Given ---
+		err0 := New("em0")
+		err1 := New("em1")
+		err2 := New("em2")
+		err3 := Wrap(errors.Join(Wrap(fmt.Errorf("w: %w", err0)), err1, err2))
+
+		// --- When ---
+		var got []string
+		for e := range Chain(err3) {
+			got = append(got, e.Error())
+		}
+
+		// --- Then ---
+		exp := []string{"w: em0\nem1\nem2", "w: em0", "em0", "em1", "em2"}
+		assert.Equal(t, exp, got)
+	})
+
+	t.Run("break", func(t *testing.T) {
+		// --- Given ---
+		err := Wrap(errors.Join(New("em0"), New("em1")))
+
+		// --- When ---
+		var got []string
+		for e := range Chain(err) {
+			got = append(got, e.Error())
+			if len(got) == 2 {
+				break
+			}
+		}
+
+		// --- Then ---
+		assert.Equal(t, []string{"em0\nem1", "em0"}, got)
+	})
+
+	t.Run("not zrr error", func(t *testing.T) {
+		// --- When ---
+		var got []*Error
+		for e := range Chain(errors.New("msg")) {
+			got = append(got, e)
+		}
+
+		// --- Then ---
+		assert.Len(t, 0, got)
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		// --- When ---
+		var got []*Error
+		for e := range Chain(nil) {
+			got = append(got, e)
+		}
+
+		// --- Then ---
+		assert.Len(t, 0, got)
+	})
+
+	t.Run("typed nil in chain", func(t *testing.T) {
+		// --- Given ---
+		var ne *nilErr
+		err := Wrap(errors.Join(ne, New("em0", "ECode0")), "ECode")
+
+		// --- When ---
+		var got []string
+		for e := range Chain(err) {
+			got = append(got, e.ErrCode())
+		}
+
+		// --- Then ---
+		assert.Equal(t, []string{"ECode", "ECode0"}, got)
+	})
+
+	t.Run("nil pointer", func(t *testing.T) {
+		// --- Given ---
+		var err *Error
+
+		// --- When ---
+		var got []*Error
+		for e := range Chain(err) {
+			got = append(got, e)
+		}
+
+		// --- Then ---
+		assert.Len(t, 0, got)
+	})
+}
+
+func Test_AllFields(t *testing.T) {
+	t.Run("merged", func(t *testing.T) {
+		// --- Given ---
+		err0 := New("em0").Str("a", "0").Str("b", "0")
+		err1 := Wrap(fmt.Errorf("wrap: %w", err0)).Str("c", "1").Str("a", "1")
+
+		// --- When ---
+		var keys []string
+		var vals []any
+		for key, val := range AllFields(err1) {
+			keys = append(keys, key)
+			vals = append(vals, val)
+		}
+
+		// --- Then ---
+		assert.Equal(t, []string{"c", "a", "b"}, keys)
+		assert.Equal(t, []any{"1", "1", "0"}, vals)
+	})
+
+	t.Run("sorted", func(t *testing.T) {
+		// --- Given ---
+		SetMetaOrder(OrderSorted)
+		t.Cleanup(func() { SetMetaOrder(OrderInsertion) })
+		err0 := New("em0").Str("a", "0").Str("b", "0")
+		err1 := Wrap(fmt.Errorf("wrap: %w", err0)).Str("c", "1")
+
+		// --- When ---
+		var keys []string
+		for key := range AllFields(err1) {
+			keys = append(keys, key)
+		}
+
+		// --- Then ---
+		assert.Equal(t, []string{"a", "b", "c"}, keys)
+	})
+
+	t.Run("maps collect", func(t *testing.T) {
+		// --- Given ---
+		err0 := Imm("em0", "ECode").Int("a", 1)
+		err1 := Wrap(errors.Join(err0, New("em1").Int("b", 2)))
+
+		// --- When ---
+		got := maps.Collect(AllFields(err1))
+
+		// --- Then ---
+		assert.Equal(t, map[string]any{"a": 1, "b": 2}, got)
+	})
+
+	t.Run("break", func(t *testing.T) {
+		// --- Given ---
+		err := New("em0").Str("a", "0").Str("b", "0")
+
+		// --- When ---
+		var keys []string
+		for key := range AllFields(err) {
+			keys = append(keys, key)
+			break
+		}
+
+		// --- Then ---
+		assert.Equal(t, []string{"a"}, keys)
+	})
+
+	t.Run("not zrr error", func(t *testing.T) {
+		// --- When ---
+		got := maps.Collect(AllFields(errors.New("msg")))
+
+		// --- Then ---
+		assert.Len(t, 0, got)
+	})
+}
@@ -0,0 +1,131 @@
+package zrr
+
+import (
+	"net/http"
+	"slices"
+)
+
+// Kind represents error kind - a category of errors independent of their
+// codes.
+type Kind string
+
+// Error kinds.
+const (
+	// NotFound represents error kind when requested entity was not found.
+	NotFound Kind = "not_found"
+
+	// Invalid represents error kind when input is invalid.
+	Invalid Kind = "invalid"
+
+	// Permission represents error kind when the caller has no permission to
+	// perform the operation.
+	Permission Kind = "permission"
+
+	// Unauthenticated represents error kind when the caller is not
+	// authenticated.
+	Unauthenticated Kind = "unauthenticated"
+
+	// Conflict represents error kind when the operation conflicts with the
+	// current state, for example the entity already exists.
+	Conflict Kind = "conflict"
+
+	// RateLimited represents error kind when the caller exceeded the limits.
+	RateLimited Kind = "rate_limited"
+
+	// Unavailable represents error kind when the service or resource is
+	// temporarily unavailable.
+	Unavailable Kind = "unavailable"
+
+	// Timeout represents error kind when the operation timed out.
+	Timeout Kind = "timeout"
+
+	// Canceled represents error kind when the operation was canceled.
+	Canceled Kind = "canceled"
+
+	// Internal represents error kind for internal errors.
+	Internal Kind = "internal"
+)
+
+// kindHTTP maps error kinds to HTTP status codes.
+var kindHTTP = map[Kind]int{
+	NotFound:        http.StatusNotFound,
+	Invalid:         http.StatusBadRequest,
+	Permission:      http.StatusForbidden,
+	Unauthenticated: http.StatusUnauthorized,
+	Conflict:        http.StatusConflict,
+	RateLimited:     http.StatusTooManyRequests,
+	Unavailable:     http.StatusServiceUnavailable,
+	Timeout:         http.StatusGatewayTimeout,
+	Canceled:        499, // Client Closed Request.
+	Internal:        http.StatusInternalServerError,
+}
+
+// kindGRPC maps error kinds to gRPC status codes.
+var kindGRPC = map[Kind]uint32{
+	NotFound:        5,  // NotFound
+	Invalid:         3,  // InvalidArgument
+	Permission:      7,  // PermissionDenied
+	Unauthenticated: 16, // Unauthenticated
+	Conflict:        6,  // AlreadyExists
+	RateLimited:     8,  // ResourceExhausted
+	Unavailable:     14, // Unavailable
+	Timeout:         4,  // DeadlineExceeded
+	Canceled:        1,  // Canceled
+	Internal:        13, // Internal
+}
+
+// HTTPStatus returns HTTP status code for the kind. It returns 500 for
+// unknown kinds.
+func (k Kind) HTTPStatus() int {
+	if status, ok := kindHTTP[k]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// GRPCCode returns gRPC status code for the kind. The value can be converted
+// to google.golang.org/grpc/codes.Code. It returns 2 (Unknown) for unknown
+// kinds.
+func (k Kind) GRPCCode() uint32 {
+	if code, ok := kindGRPC[k]; ok {
+		return code
+	}
+	return 2
+}
+
+// SetKind sets error kind. When the error is immutable the kind is set on
+// its mutable copy.
+func (e *Error) SetKind(k Kind) *Error {
+	ne := e.mutable()
+	ne.kind = k
+	return ne
+}
+
+// GetKind returns kind of err. The err chain is walked and the first Error
+// instance with kind set with SetKind or with a code registered with kind
+// decides. It returns empty kind when the kind is not set.
+func GetKind(err error) Kind {
+	for e := range Chain(err) {
+		if e.kind != "" {
+			return e.kind
+		}
+		if info, ok := Lookup(e.code); ok && info.Kind != "" {
+			return info.Kind
+		}
+	}
+	return ""
+}
+
+// IsKind returns true if err is of any of the kinds.
+func IsKind(err error, kinds ...Kind) bool {
+	kind := GetKind(err)
+	return kind != "" && slices.Contains(kinds, kind)
+}
+
+// HTTPStatus returns HTTP status code for err based on its kind. It returns
+// 500 when err has no kind.
+func HTTPStatus(err error) int { return GetKind(err).HTTPStatus() }
+
+// GRPCCode returns gRPC status code for err based on its kind. It returns 2
+// (Unknown) when err has no kind.
+func GRPCCode(err error) uint32 { return GetKind(err).GRPCCode() }
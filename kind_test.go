@@ -0,0 +1,160 @@
+package zrr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+)
+
+func Test_Kind_HTTPStatus(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		kind Kind
+		exp  int
+	}{
+		{"not found", NotFound, 404},
+		{"invalid", Invalid, 400},
+		{"permission", Permission, 403},
+		{"unauthenticated", Unauthenticated, 401},
+		{"conflict", Conflict, 409},
+		{"rate limited", RateLimited, 429},
+		{"unavailable", Unavailable, 503},
+		{"timeout", Timeout, 504},
+		{"canceled", Canceled, 499},
+		{"internal", Internal, 500},
+		{"empty", "", 500},
+		{"unknown", "other", 500},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			assert.Equal(t, tc.exp, tc.kind.HTTPStatus())
+		})
+	}
+}
+
+func Test_Kind_GRPCCode(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		kind Kind
+		exp  uint32
+	}{
+		{"not found", NotFound, 5},
+		{"invalid", Invalid, 3},
+		{"permission", Permission, 7},
+		{"unauthenticated", Unauthenticated, 16},
+		{"conflict", Conflict, 6},
+		{"rate limited", RateLimited, 8},
+		{"unavailable", Unavailable, 14},
+		{"timeout", Timeout, 4},
+		{"canceled", Canceled, 1},
+		{"internal", Internal, 13},
+		{"empty", "", 2},
+		{"unknown", "other", 2},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			assert.Equal(t, tc.exp, tc.kind.GRPCCode())
+		})
+	}
+}
+
+func Test_Error_SetKind(t *testing.T) {
+	t.Run("mutable", func(t *testing.T) {
+		// --- Given ---
+		err := New("em0")
+
+		// --- When ---
+		got := err.SetKind(NotFound)
+
+		// --- Then ---
+		assert.Same(t, err, got)
+		assert.Equal(t, NotFound, GetKind(got))
+	})
+
+	t.Run("immutable", func(t *testing.T) {
+		// --- Given ---
+		err := Imm("em0")
+
+		// --- When ---
+		got := err.SetKind(NotFound)
+
+		// --- Then ---
+		assert.NotSame(t, err, got)
+		assert.Equal(t, NotFound, GetKind(got))
+		assert.Equal(t, Kind(""), GetKind(err))
+	})
+}
+
+func Test_GetKind(t *testing.T) {
+	resetRegistry(t)
+	Register(CodeInfo{Code: "ECUserNotFound", Kind: NotFound})
+
+	imm := Imm("em0", "ECode")
+	imm.kind = Conflict
+
+	tt := []struct {
+		testN string
+
+		exp Kind
+		err error
+	}{
+		{"not set", "", New("em0")},
+		{"set", Invalid, New("em0").SetKind(Invalid)},
+		{"registered", NotFound, New("em0", "ECUserNotFound")},
+		{"set overrides registered", Internal, New("em0", "ECUserNotFound").SetKind(Internal)},
+		{"wrapped", NotFound, Wrap(fmt.Errorf("w: %w", New("em0", "ECUserNotFound")))},
+		{"immutable clone", Conflict, imm.Str("k", "v")},
+		{"package code", Invalid, ErrInvJSON},
+		{"context deadline", Timeout, New("em0", ECDeadline)},
+		{"panic", Internal, FromPanic("boom")},
+		{"std error", "", errors.New("em0")},
+		{"nil", "", nil},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			assert.Equal(t, tc.exp, GetKind(tc.err))
+		})
+	}
+}
+
+func Test_GetKind_canceledContext(t *testing.T) {
+	// --- Given ---
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// --- When ---
+	got := GetKind(WrapCtxErr(ctx, ctx.Err()))
+
+	// --- Then ---
+	assert.Equal(t, Canceled, got)
+}
+
+func Test_IsKind(t *testing.T) {
+	// --- Given ---
+	err := fmt.Errorf("w: %w", New("em0").SetKind(NotFound))
+
+	// --- Then ---
+	assert.True(t, IsKind(err, NotFound))
+	assert.True(t, IsKind(err, Invalid, NotFound))
+	assert.False(t, IsKind(err, Invalid))
+	assert.False(t, IsKind(New("em0"), ""))
+	assert.False(t, IsKind(nil, NotFound))
+}
+
+func Test_HTTPStatus(t *testing.T) {
+	assert.Equal(t, 404, HTTPStatus(New("em0").SetKind(NotFound)))
+	assert.Equal(t, 500, HTTPStatus(errors.New("em0")))
+}
+
+func Test_GRPCCode(t *testing.T) {
+	assert.Equal(t, uint32(5), GRPCCode(New("em0").SetKind(NotFound)))
+	assert.Equal(t, uint32(2), GRPCCode(errors.New("em0")))
+}
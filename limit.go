@@ -0,0 +1,136 @@
+package zrr
+
+import (
+	"encoding/json"
+	"sync/atomic"
+	"unicode/utf8"
+)
+
+// TruncMarker is appended to truncated metadata keys and string values.
+const TruncMarker = "...[truncated]"
+
+// KeyDropped is the metadata key for the number of metadata keys dropped
+// because of the limits. It's added only to the output.
+const KeyDropped = "meta_dropped"
+
+// Limits represents limits applied to error message and metadata when it's
+// serialized or logged. Zero value means no limit.
+type Limits struct {
+	// Maximum number of metadata keys.
+	MaxKeys int
+
+	// Maximum length of metadata key in bytes. Keys which are not unique
+	// after truncation are dropped.
+	MaxKeyLen int
+
+	// Maximum length of string metadata value in bytes. It also applies to
+	// values substituted into message placeholders.
+	MaxStrLen int
+
+	// Maximum size of serialized error message and metadata key value pairs
+	// in bytes. Longer message is truncated, metadata keys which don't fit
+	// in what's left are dropped. The code and other top level fields are
+	// not counted.
+	MaxSize int
+}
+
+// limits is the package wide limits.
+var limits atomic.Pointer[Limits]
+
+func init() { limits.Store(&Limits{}) }
+
+// SetLimits sets the package wide limits. The limits are enforced in
+// MarshalJSON, the %+v verb and LogValue. By default, there are no limits.
+func SetLimits(l Limits) { limits.Store(&l) }
+
+// GetLimits returns the package wide limits.
+func GetLimits() Limits { return *limits.Load() }
+
+// field represents metadata key value pair.
+type field struct {
+	key string
+	val any
+}
+
+// outMsg returns the error message as it should be output with MaxSize
+// limit applied, and its serialized size counted against the limit.
+func outMsg(msg string) (string, int) {
+	lim := GetLimits()
+	if lim.MaxSize <= 0 {
+		return msg, 0
+	}
+	if len(msg) > lim.MaxSize {
+		msg = truncate(msg, max(lim.MaxSize-len(TruncMarker), 0))
+	}
+	data, _ := json.Marshal(msg)
+	return msg, len(data)
+}
+
+// outFields returns metadata key value pairs as they should be output, with
+// sensitive values masked (see Redact) and the limits set with SetLimits
+// applied. The size is the number of bytes already counted against MaxSize
+// (see outMsg). Keys over the limits and keys which are not unique after
+// truncation are dropped and their number is added under KeyDropped key.
+func outFields(keys []string, meta map[string]any, size int) []field {
+	lim := GetLimits()
+	fields := make([]field, 0, len(keys))
+	var seen map[string]struct{}
+	if lim.MaxKeyLen > 0 {
+		seen = make(map[string]struct{}, len(keys))
+	}
+	var dropped int
+	for _, key := range keys {
+		if lim.MaxKeys > 0 && len(fields) >= lim.MaxKeys {
+			dropped++
+			continue
+		}
+		val := Redact(key, meta[key])
+		if lim.MaxStrLen > 0 {
+			if s, ok := val.(string); ok {
+				val = truncate(s, lim.MaxStrLen)
+			}
+		}
+		if lim.MaxKeyLen > 0 {
+			key = truncate(key, lim.MaxKeyLen)
+			if _, ok := seen[key]; ok {
+				dropped++
+				continue
+			}
+			seen[key] = struct{}{}
+		}
+		if lim.MaxSize > 0 {
+			n := fieldSize(key, val)
+			if size+n > lim.MaxSize {
+				dropped++
+				continue
+			}
+			size += n
+		}
+		fields = append(fields, field{key, val})
+	}
+	if dropped > 0 {
+		fields = append(fields, field{KeyDropped, dropped})
+	}
+	return fields
+}
+
+// fieldSize returns the size of the key value pair serialized as JSON
+// object member including the separator.
+func fieldSize(key string, val any) int {
+	k, _ := json.Marshal(key)
+	v, _ := json.Marshal(val)
+	return len(k) + len(v) + 2
+}
+
+// truncate truncates s to at most n bytes, without splitting multibyte
+// characters, and appends TruncMarker. It returns s when it's not longer
+// than n.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n] + TruncMarker
+}
@@ -0,0 +1,298 @@
+package zrr
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+)
+
+// setLimits sets the package wide metadata limits for the duration of
+// the test.
+func setLimits(t *testing.T, l Limits) {
+	t.Helper()
+	saved := GetLimits()
+	t.Cleanup(func() { SetLimits(saved) })
+	SetLimits(l)
+}
+
+func Test_SetLimits(t *testing.T) {
+	// --- Given ---
+	setLimits(t, Limits{})
+
+	// --- When ---
+	SetLimits(Limits{MaxKeys: 1, MaxKeyLen: 2, MaxStrLen: 3, MaxSize: 4})
+
+	// --- Then ---
+	want := Limits{MaxKeys: 1, MaxKeyLen: 2, MaxStrLen: 3, MaxSize: 4}
+	assert.Equal(t, want, GetLimits())
+}
+
+func Test_outFields(t *testing.T) {
+	t.Run("no limits", func(t *testing.T) {
+		// --- Given ---
+		setLimits(t, Limits{})
+		keys := []string{"b", "a"}
+		meta := map[string]any{"a": strings.Repeat("x", 100), "b": 1}
+
+		// --- When ---
+		have := outFields(keys, meta, 0)
+
+		// --- Then ---
+		want := []field{{"b", 1}, {"a", strings.Repeat("x", 100)}}
+		assert.Equal(t, want, have)
+	})
+
+	t.Run("max keys", func(t *testing.T) {
+		// --- Given ---
+		setLimits(t, Limits{MaxKeys: 2})
+		keys := []string{"a", "b", "c", "d"}
+		meta := map[string]any{"a": 1, "b": 2, "c": 3, "d": 4}
+
+		// --- When ---
+		have := outFields(keys, meta, 0)
+
+		// --- Then ---
+		want := []field{{"a", 1}, {"b", 2}, {KeyDropped, 2}}
+		assert.Equal(t, want, have)
+	})
+
+	t.Run("max key length", func(t *testing.T) {
+		// --- Given ---
+		setLimits(t, Limits{MaxKeyLen: 3})
+		keys := []string{"abcdef", "abc"}
+		meta := map[string]any{"abcdef": 1, "abc": 2}
+
+		// --- When ---
+		have := outFields(keys, meta, 0)
+
+		// --- Then ---
+		want := []field{{"abc" + TruncMarker, 1}, {"abc", 2}}
+		assert.Equal(t, want, have)
+	})
+
+	t.Run("max key length collision", func(t *testing.T) {
+		// --- Given ---
+		setLimits(t, Limits{MaxKeyLen: 3})
+		keys := []string{"abcd", "abce", "abc" + TruncMarker, "x"}
+		meta := map[string]any{"abcd": 1, "abce": 2, "abc" + TruncMarker: 3, "x": 4}
+
+		// --- When ---
+		have := outFields(keys, meta, 0)
+
+		// --- Then ---
+		want := []field{{"abc" + TruncMarker, 1}, {"x", 4}, {KeyDropped, 2}}
+		assert.Equal(t, want, have)
+	})
+
+	t.Run("max string length", func(t *testing.T) {
+		// --- Given ---
+		setLimits(t, Limits{MaxStrLen: 3})
+		keys := []string{"a", "b", "c"}
+		meta := map[string]any{"a": "abcdef", "b": "abc", "c": 123456}
+
+		// --- When ---
+		have := outFields(keys, meta, 0)
+
+		// --- Then ---
+		want := []field{{"a", "abc" + TruncMarker}, {"b", "abc"}, {"c", 123456}}
+		assert.Equal(t, want, have)
+	})
+
+	t.Run("max size", func(t *testing.T) {
+		// --- Given ---
+		setLimits(t, Limits{MaxSize: 20})
+		keys := []string{"a", "b", "c"}
+		meta := map[string]any{"a": "12345", "b": strings.Repeat("x", 20), "c": 1}
+
+		// --- When ---
+		have := outFields(keys, meta, 0)
+
+		// --- Then ---
+		want := []field{{"a", "12345"}, {"c", 1}, {KeyDropped, 1}}
+		assert.Equal(t, want, have)
+	})
+
+	t.Run("max size with used size", func(t *testing.T) {
+		// --- Given ---
+		setLimits(t, Limits{MaxSize: 20})
+		keys := []string{"a", "c"}
+		meta := map[string]any{"a": "12345", "c": 1}
+
+		// --- When ---
+		have := outFields(keys, meta, 12)
+
+		// --- Then ---
+		want := []field{{"c", 1}, {KeyDropped, 1}}
+		assert.Equal(t, want, have)
+	})
+
+	t.Run("redacted", func(t *testing.T) {
+		// --- Given ---
+		setLimits(t, Limits{MaxStrLen: 3})
+		keys := []string{"a"}
+		meta := map[string]any{"a": secret{"abcdef"}}
+
+		// --- When ---
+		have := outFields(keys, meta, 0)
+
+		// --- Then ---
+		want := []field{{"a", "[RE" + TruncMarker}}
+		assert.Equal(t, want, have)
+	})
+}
+
+func Test_truncate(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		exp string
+		s   string
+		n   int
+	}{
+		{"shorter", "abc", "abc", 5},
+		{"equal", "abc", "abc", 3},
+		{"longer", "ab" + TruncMarker, "abc", 2},
+		{"multibyte", "a" + TruncMarker, "aół", 2},
+		{"multibyte boundary", "aó" + TruncMarker, "aół", 3},
+		{"zero", TruncMarker, "ół", 1},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			assert.Equal(t, tc.exp, truncate(tc.s, tc.n))
+		})
+	}
+}
+
+func Test_Error_limits(t *testing.T) {
+	// --- Given ---
+	setLimits(t, Limits{MaxKeys: 2, MaxStrLen: 4})
+	err := New("em0", "ECode").
+		Str("body", "abcdefgh").
+		Int("status", 500).
+		Str("host", "db1")
+
+	t.Run("JSON", func(t *testing.T) {
+		// --- When ---
+		data, jErr := json.Marshal(err)
+
+		// --- Then ---
+		assert.NoError(t, jErr)
+		want := `{
+			"error":"em0",
+			"code":"ECode",
+			"meta":{"body":"abcd...[truncated]","status":500,"meta_dropped":1}
+		}`
+		assert.JSON(t, want, string(data))
+	})
+
+	t.Run("fmt", func(t *testing.T) {
+		// --- When ---
+		have := fmt.Sprintf("%+v", err)
+
+		// --- Then ---
+		want := "em0 [ECode] body=abcd...[truncated] status=500 meta_dropped=1"
+		assert.Equal(t, want, have)
+	})
+
+	t.Run("slog", func(t *testing.T) {
+		// --- Given ---
+		buf := &bytes.Buffer{}
+		log := slog.New(slog.NewJSONHandler(buf, nil))
+
+		// --- When ---
+		log.Info("msg", "err", err)
+
+		// --- Then ---
+		want := `"meta":{"body":"abcd...[truncated]","status":500,"meta_dropped":1}`
+		assert.Contain(t, want, buf.String())
+	})
+
+	t.Run("max size includes message", func(t *testing.T) {
+		// --- Given ---
+		setLimits(t, Limits{MaxSize: 30})
+		msg := strings.Repeat("x", 10)
+		err := New(msg, "ECode").Int("a", 1).Str("b", strings.Repeat("x", 20))
+
+		// --- When ---
+		data, jErr := json.Marshal(err)
+
+		// --- Then ---
+		assert.NoError(t, jErr)
+		want := fmt.Sprintf(`{
+			"error":%q,
+			"code":"ECode",
+			"meta":{"a":1,"meta_dropped":1}
+		}`, msg)
+		assert.JSON(t, want, string(data))
+	})
+
+	t.Run("message over max size", func(t *testing.T) {
+		// --- Given ---
+		setLimits(t, Limits{MaxSize: 30})
+		err := New(strings.Repeat("x", 100), "ECode").Int("a", 1)
+
+		// --- When ---
+		data, jErr := json.Marshal(err)
+
+		// --- Then ---
+		assert.NoError(t, jErr)
+		want := fmt.Sprintf(`{
+			"error":%q,
+			"code":"ECode",
+			"meta":{"meta_dropped":1}
+		}`, strings.Repeat("x", 16)+TruncMarker)
+		assert.JSON(t, want, string(data))
+	})
+
+	t.Run("placeholder", func(t *testing.T) {
+		// --- Given ---
+		setLimits(t, Limits{MaxStrLen: 10, MaxSize: 50})
+		err := New("body: {body}", "ECode").Str("body", strings.Repeat("x", 200))
+
+		// --- When ---
+		data, jErr := json.Marshal(err)
+
+		// --- Then ---
+		assert.NoError(t, jErr)
+		want := fmt.Sprintf(`{
+			"error":"body: %s",
+			"code":"ECode",
+			"meta":{"meta_dropped":1}
+		}`, strings.Repeat("x", 10)+TruncMarker)
+		assert.JSON(t, want, string(data))
+		assert.Equal(t, "body: "+strings.Repeat("x", 10)+TruncMarker, err.Error())
+	})
+
+	t.Run("placeholder fmt and slog", func(t *testing.T) {
+		// --- Given ---
+		setLimits(t, Limits{MaxStrLen: 10, MaxSize: 50})
+		err := New("body: {body}", "ECode").Str("body", strings.Repeat("x", 200))
+		buf := &bytes.Buffer{}
+		log := slog.New(slog.NewJSONHandler(buf, nil))
+
+		// --- When ---
+		have := fmt.Sprintf("%+v", err)
+		log.Info("msg", "err", err)
+
+		// --- Then ---
+		body := strings.Repeat("x", 10) + TruncMarker
+		assert.Equal(t, "body: "+body+" [ECode] meta_dropped=1", have)
+		want := fmt.Sprintf(`"error":"body: %s"`, body)
+		assert.Contain(t, want, buf.String())
+		assert.NotContain(t, strings.Repeat("x", 11), buf.String())
+	})
+
+	t.Run("values are not changed", func(t *testing.T) {
+		// --- When ---
+		have, _ := GetStr(err, "body")
+
+		// --- Then ---
+		assert.Equal(t, "abcdefgh", have)
+	})
+}
@@ -0,0 +1,161 @@
+package zrr
+
+import (
+	"reflect"
+	"slices"
+)
+
+// AppendStr appends the string s to the list of strings represented by the
+// key. The key will be added if it doesn't exist. If the key already exists
+// and is not a string or a list of strings, both values are kept in a list
+// of any values.
+func (e *Error) AppendStr(key string, s string) *Error {
+	return e.appendList(key, s)
+}
+
+// AppendInt appends the integer i to the list of integers represented by
+// the key. The key will be added if it doesn't exist. If the key already
+// exists and is not an integer or a list of integers, both values are kept
+// in a list of any values.
+func (e *Error) AppendInt(key string, i int) *Error {
+	return e.appendList(key, i)
+}
+
+// Append appends the value v to the list represented by the key. The key
+// will be added if it doesn't exist. When the existing value and v are of
+// the same type the list is typed (for example []float64), otherwise a list
+// of any values is used. When v is a list its elements are appended.
+func (e *Error) Append(key string, v any) *Error {
+	return e.appendList(key, v)
+}
+
+// appendList appends v to the list represented by the key. When the error
+// is immutable the value is appended on its mutable copy.
+func (e *Error) appendList(key string, v any) *Error {
+	ne := e.mutable()
+	if old, ok := ne.meta[key]; ok {
+		ne.set(key, appendValue(old, v))
+		return ne
+	}
+	ne.set(key, appendValue(nil, v))
+	return ne
+}
+
+// GetStrs returns the key as a list of strings if err is an instance of
+// Error and key exists. Lists from all Error instances in the err chain are
+// concatenated, the deepest instance values come first. If key does not
+// exist, or it's not a string or a list of strings it will return false as
+// the second return value.
+func GetStrs(err error, key string) ([]string, bool) {
+	return getList[string](err, key)
+}
+
+// GetInts returns the key as a list of integers if err is an instance of
+// Error and key exists. Lists from all Error instances in the err chain are
+// concatenated, the deepest instance values come first. If key does not
+// exist, or it's not an integer or a list of integers it will return false
+// as the second return value.
+func GetInts(err error, key string) ([]int, bool) {
+	return getList[int](err, key)
+}
+
+// GetList returns the key as a list of any values if err is an instance of
+// Error and key exists. Lists from all Error instances in the err chain are
+// concatenated, the deepest instance values come first. Non list values are
+// returned as a single element list. If key does not exist it will return
+// false as the second return value.
+func GetList(err error, key string) ([]any, bool) {
+	return getList[any](err, key)
+}
+
+// getList returns the key as a list of T values concatenated from all Error
+// instances in the err chain.
+func getList[T any](err error, key string) ([]T, bool) {
+	var vals []any
+	for e := range metaLayers(err) {
+		if val, ok := e.get(key); ok {
+			vals = append(vals, val)
+		}
+	}
+	if len(vals) == 0 {
+		return nil, false
+	}
+
+	var ret []T
+	for _, val := range slices.Backward(vals) {
+		for _, v := range listElems(val) {
+			t, ok := v.(T)
+			if !ok && (v != nil || reflect.TypeFor[T]().Kind() != reflect.Interface) {
+				return nil, false
+			}
+			ret = append(ret, t)
+		}
+	}
+	return ret, true
+}
+
+// isList returns true if v is a list. Byte slices are not considered lists.
+func isList(v any) bool {
+	if v == nil {
+		return false
+	}
+	rv := reflect.ValueOf(v)
+	return rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() != reflect.Uint8
+}
+
+// listElems returns elements of the list v. When v is not a list a single
+// element slice is returned.
+func listElems(v any) []any {
+	if !isList(v) {
+		return []any{v}
+	}
+	rv := reflect.ValueOf(v)
+	elems := make([]any, rv.Len())
+	for i := range elems {
+		elems[i] = rv.Index(i).Interface()
+	}
+	return elems
+}
+
+// elemType returns the type of list v elements or the type of v when it's
+// not a list. It returns nil when v is nil.
+func elemType(v any) reflect.Type {
+	if v == nil {
+		return nil
+	}
+	if isList(v) {
+		return reflect.TypeOf(v).Elem()
+	}
+	return reflect.TypeOf(v)
+}
+
+// appendValue appends val to the list represented by old and returns a new
+// list. Lists are flattened, so appending a list appends its elements. When
+// elements of old and val have the same type the returned list is typed,
+// otherwise it's a list of any values. When old is nil a list with val
+// elements is returned.
+func appendValue(old, val any) any {
+	typ := elemType(val)
+	if old != nil && elemType(old) != typ {
+		typ = nil
+	}
+	if typ == nil {
+		typ = reflect.TypeFor[any]()
+	}
+
+	var elems []any
+	if old != nil {
+		elems = listElems(old)
+	}
+	elems = append(elems, listElems(val)...)
+
+	lst := reflect.MakeSlice(reflect.SliceOf(typ), 0, len(elems))
+	for _, elem := range elems {
+		if elem == nil {
+			lst = reflect.Append(lst, reflect.Zero(typ))
+			continue
+		}
+		lst = reflect.Append(lst, reflect.ValueOf(elem))
+	}
+	return lst.Interface()
+}
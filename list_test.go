@@ -0,0 +1,335 @@
+package zrr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+)
+
+func Test_Error_AppendStr(t *testing.T) {
+	t.Run("create", func(t *testing.T) {
+		// --- When ---
+		err := New("em0").AppendStr("key0", "a")
+
+		// --- Then ---
+		assert.Equal(t, map[string]any{"key0": []string{"a"}}, err.GetMetadata())
+	})
+
+	t.Run("append", func(t *testing.T) {
+		// --- When ---
+		err := New("em0").AppendStr("key0", "a").AppendStr("key0", "b")
+
+		// --- Then ---
+		assert.Equal(t, map[string]any{"key0": []string{"a", "b"}}, err.GetMetadata())
+	})
+
+	t.Run("existing string", func(t *testing.T) {
+		// --- When ---
+		err := New("em0").Str("key0", "a").AppendStr("key0", "b")
+
+		// --- Then ---
+		assert.Equal(t, map[string]any{"key0": []string{"a", "b"}}, err.GetMetadata())
+	})
+
+	t.Run("existing not string", func(t *testing.T) {
+		// --- When ---
+		err := New("em0").Int("key0", 1).AppendStr("key0", "b")
+
+		// --- Then ---
+		assert.Equal(t, map[string]any{"key0": []any{1, "b"}}, err.GetMetadata())
+	})
+
+	t.Run("immutable", func(t *testing.T) {
+		// --- Given ---
+		imm := Imm("em0", "ECode")
+		imm.set("key0", []string{"a"})
+
+		// --- When ---
+		err := imm.AppendStr("key0", "b")
+
+		// --- Then ---
+		assert.NotSame(t, imm, err)
+		assert.Equal(t, map[string]any{"key0": []string{"a", "b"}}, err.GetMetadata())
+		assert.Equal(t, map[string]any{"key0": []string{"a"}}, imm.GetMetadata())
+	})
+}
+
+func Test_Error_AppendInt(t *testing.T) {
+	t.Run("append", func(t *testing.T) {
+		// --- When ---
+		err := New("em0").AppendInt("key0", 1).AppendInt("key0", 2)
+
+		// --- Then ---
+		assert.Equal(t, map[string]any{"key0": []int{1, 2}}, err.GetMetadata())
+	})
+
+	t.Run("existing int", func(t *testing.T) {
+		// --- When ---
+		err := New("em0").Int("key0", 1).AppendInt("key0", 2)
+
+		// --- Then ---
+		assert.Equal(t, map[string]any{"key0": []int{1, 2}}, err.GetMetadata())
+	})
+
+	t.Run("existing list of strings", func(t *testing.T) {
+		// --- When ---
+		err := New("em0").AppendStr("key0", "a").AppendInt("key0", 2)
+
+		// --- Then ---
+		assert.Equal(t, map[string]any{"key0": []any{"a", 2}}, err.GetMetadata())
+	})
+}
+
+func Test_Error_Append(t *testing.T) {
+	t.Run("typed", func(t *testing.T) {
+		// --- When ---
+		err := New("em0").Append("key0", 1.5).Append("key0", 2.5)
+
+		// --- Then ---
+		assert.Equal(t, map[string]any{"key0": []float64{1.5, 2.5}}, err.GetMetadata())
+	})
+
+	t.Run("mixed", func(t *testing.T) {
+		// --- When ---
+		err := New("em0").Append("key0", 1.5).Append("key0", true)
+
+		// --- Then ---
+		assert.Equal(t, map[string]any{"key0": []any{1.5, true}}, err.GetMetadata())
+	})
+
+	t.Run("list", func(t *testing.T) {
+		// --- When ---
+		err := New("em0").AppendStr("key0", "a").Append("key0", []string{"b", "c"})
+
+		// --- Then ---
+		exp := map[string]any{"key0": []string{"a", "b", "c"}}
+		assert.Equal(t, exp, err.GetMetadata())
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		// --- When ---
+		err := New("em0").AppendStr("key0", "a").Append("key0", nil)
+
+		// --- Then ---
+		assert.Equal(t, map[string]any{"key0": []any{"a", nil}}, err.GetMetadata())
+	})
+
+	t.Run("does not share backing array", func(t *testing.T) {
+		// --- Given ---
+		lst := make([]string, 1, 10)
+		lst[0] = "a"
+		err := New("em0").with("key0", lst)
+
+		// --- When ---
+		err.AppendStr("key0", "b")
+
+		// --- Then ---
+		assert.Equal(t, []string{"a"}, lst)
+		assert.Equal(t, "", lst[:2][1])
+	})
+}
+
+func Test_Error_list_MarshalJSON(t *testing.T) {
+	// --- Given ---
+	e := New("test msg").AppendStr("ids", "a").AppendStr("ids", "b")
+
+	// --- When ---
+	data, err := json.Marshal(e)
+
+	// --- Then ---
+	assert.NoError(t, err)
+	exp := `{"error":"test msg","code":"","meta":{"ids":["a","b"]}}`
+	assert.Equal(t, exp, string(data))
+}
+
+func Test_GetStrs(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		err   error
+		value []string
+		exist bool
+	}{
+		{"1", New("em0"), nil, false},
+		{"2", New("em0").AppendStr("key0", "a"), []string{"a"}, true},
+		{"3", New("em0").Str("key0", "a"), []string{"a"}, true},
+		{"4", New("em0").Int("key0", 1), nil, false},
+		{"5", New("em0").Append("key0", []any{"a", "b"}), []string{"a", "b"}, true},
+		{"6", New("em0").Append("key0", []any{"a", 1}), nil, false},
+		{"7", errors.New("message"), nil, false},
+		{"8", nil, nil, false},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- When ---
+			value, exist := GetStrs(tc.err, "key0")
+
+			// --- Then ---
+			assert.Equal(t, tc.exist, exist)
+			assert.Equal(t, tc.value, value)
+		})
+	}
+}
+
+func Test_GetStrs_chain(t *testing.T) {
+	t.Run("concatenated", func(t *testing.T) {
+		// --- Given ---
+		err0 := New("em0").AppendStr("ids", "a").AppendStr("ids", "b")
+		err1 := Wrap(fmt.Errorf("wrap: %w", err0)).AppendStr("ids", "c")
+
+		// --- When ---
+		got, ok := GetStrs(err1, "ids")
+
+		// --- Then ---
+		assert.True(t, ok)
+		assert.Equal(t, []string{"a", "b", "c"}, got)
+	})
+
+	t.Run("immutable not duplicated", func(t *testing.T) {
+		// --- Given ---
+		imm := Imm("em0", "ECode")
+		imm.set("ids", []string{"a"})
+		err := imm.AppendStr("ids", "b")
+
+		// --- When ---
+		got, ok := GetStrs(err, "ids")
+
+		// --- Then ---
+		assert.True(t, ok)
+		assert.Equal(t, []string{"a", "b"}, got)
+	})
+}
+
+func Test_GetInts(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		err   error
+		value []int
+		exist bool
+	}{
+		{"1", New("em0"), nil, false},
+		{"2", New("em0").AppendInt("key0", 1), []int{1}, true},
+		{"3", New("em0").Int("key0", 1), []int{1}, true},
+		{"4", New("em0").Str("key0", "a"), nil, false},
+		{"5", New("em0").Int64("key0", 1), nil, false},
+		{"6", nil, nil, false},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- When ---
+			value, exist := GetInts(tc.err, "key0")
+
+			// --- Then ---
+			assert.Equal(t, tc.exist, exist)
+			assert.Equal(t, tc.value, value)
+		})
+	}
+}
+
+func Test_GetList(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		err   error
+		value []any
+		exist bool
+	}{
+		{"1", New("em0"), nil, false},
+		{"2", New("em0").AppendInt("key0", 1), []any{1}, true},
+		{"3", New("em0").Str("key0", "a"), []any{"a"}, true},
+		{"4", New("em0").Append("key0", 1).Append("key0", "a"), []any{1, "a"}, true},
+		{"5", New("em0").with("key0", nil), []any{nil}, true},
+		{"6", nil, nil, false},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- When ---
+			value, exist := GetList(tc.err, "key0")
+
+			// --- Then ---
+			assert.Equal(t, tc.exist, exist)
+			assert.Equal(t, tc.value, value)
+		})
+	}
+}
+
+func Test_AllFields_lists(t *testing.T) {
+	t.Run("concatenated", func(t *testing.T) {
+		// --- Given ---
+		err0 := New("em0").AppendInt("ids", 1).Str("key", "a")
+		err1 := Wrap(fmt.Errorf("wrap: %w", err0)).AppendInt("ids", 2).Str("key", "b")
+
+		// --- When ---
+		got := maps.Collect(AllFields(err1))
+
+		// --- Then ---
+		assert.Equal(t, map[string]any{"ids": []int{1, 2}, "key": "b"}, got)
+	})
+
+	t.Run("outer scalar wins", func(t *testing.T) {
+		// --- Given ---
+		err0 := New("em0").AppendInt("ids", 1)
+		err1 := Wrap(fmt.Errorf("wrap: %w", err0)).Int("ids", 2)
+
+		// --- When ---
+		got := maps.Collect(AllFields(err1))
+
+		// --- Then ---
+		assert.Equal(t, map[string]any{"ids": 2}, got)
+	})
+
+	t.Run("immutable not duplicated", func(t *testing.T) {
+		// --- Given ---
+		imm := Imm("em0", "ECode")
+		imm.set("ids", []int{1})
+		err := imm.AppendInt("ids", 2)
+
+		// --- When ---
+		got := maps.Collect(AllFields(err))
+
+		// --- Then ---
+		assert.Equal(t, map[string]any{"ids": []int{1, 2}}, got)
+	})
+}
+
+func Test_appendValue(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		old any
+		val any
+		exp any
+	}{
+		{"nil old", nil, "a", []string{"a"}},
+		{"nil old list", nil, []int{1}, []int{1}},
+		{"nil both", nil, nil, []any{nil}},
+		{"scalars same type", "a", "b", []string{"a", "b"}},
+		{"scalars different type", "a", 1, []any{"a", 1}},
+		{"list and scalar", []string{"a"}, "b", []string{"a", "b"}},
+		{"lists", []int{1}, []int{2, 3}, []int{1, 2, 3}},
+		{"any list", []any{1}, 2, []any{1, 2}},
+		{"bytes are scalar", []byte("a"), []byte("b"), [][]byte{[]byte("a"), []byte("b")}},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			assert.Equal(t, tc.exp, appendValue(tc.old, tc.val))
+		})
+	}
+}
+
+func Test_isList(t *testing.T) {
+	assert.True(t, isList([]string{}))
+	assert.True(t, isList([]any{}))
+	assert.False(t, isList([]byte{}))
+	assert.False(t, isList("a"))
+	assert.False(t, isList(nil))
+}
@@ -0,0 +1,111 @@
+package zrr
+
+import (
+	"maps"
+	"slices"
+)
+
+// ECMetaConflict represents metadata key conflict error code.
+const ECMetaConflict = "ECMetaConflict"
+
+// ErrMetaConflict represents package level error indicating metadata key
+// conflict when merging metadata with MergeConflict option.
+var ErrMetaConflict = Imm("metadata key conflict", ECMetaConflict)
+
+// MergeOption represents an option used when merging metadata.
+type MergeOption func(*mergeOpts)
+
+// mergeOpts represents metadata merge options.
+type mergeOpts struct {
+	strategy mergeStrategy // Strategy for keys which already exist.
+	prefix   string        // Prefix added to source keys.
+}
+
+// mergeStrategy represents strategy used when the merged key already exists.
+type mergeStrategy int
+
+const (
+	mergeOverwrite mergeStrategy = iota
+	mergeKeep
+	mergeAppend
+	mergeConflict
+)
+
+// MergeOverwrite is a merge option overwriting existing keys with values from
+// the source. This is the default strategy.
+func MergeOverwrite(o *mergeOpts) { o.strategy = mergeOverwrite }
+
+// MergeKeep is a merge option keeping values of existing keys.
+func MergeKeep(o *mergeOpts) { o.strategy = mergeKeep }
+
+// MergeAppend is a merge option appending values from the source to the
+// existing values, creating a list when necessary.
+func MergeAppend(o *mergeOpts) { o.strategy = mergeAppend }
+
+// MergeConflict is a merge option causing the merge to fail with
+// ErrMetaConflict when any of the source keys already exist.
+func MergeConflict(o *mergeOpts) { o.strategy = mergeConflict }
+
+// MergePrefix is a merge option prefixing all source keys with prefix.
+func MergePrefix(prefix string) MergeOption {
+	return func(o *mergeOpts) { o.prefix = prefix }
+}
+
+// MergeMeta merges src metadata into error metadata. Keys are added in
+// lexicographical order. By default, existing keys are overwritten, use
+// options to change this behaviour.
+//
+// The returned instance might be different from the one this method is
+// called on if the error is immutable, in which case only one copy is made.
+// On error, the metadata is not changed and the receiver is returned.
+func (e *Error) MergeMeta(src map[string]any, opts ...MergeOption) (*Error, error) {
+	keys := slices.Sorted(maps.Keys(src))
+	return e.merge(keys, src, opts...)
+}
+
+// MergeFrom merges metadata from an instance which implements MetadataGetter
+// the same way MergeMeta does. When src is an Error instance its keys are
+// added in its metadata order.
+func (e *Error) MergeFrom(src MetadataGetter, opts ...MergeOption) (*Error, error) {
+	if se, ok := src.(*Error); ok && se != nil {
+		return e.merge(se.metaKeys(), se.meta, opts...)
+	}
+	return e.MergeMeta(src.GetMetadata(), opts...)
+}
+
+// merge merges keys from src map using options.
+func (e *Error) merge(keys []string, src map[string]any, opts ...MergeOption) (*Error, error) {
+	if len(keys) == 0 {
+		return e, nil
+	}
+
+	cfg := mergeOpts{}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	if cfg.strategy == mergeConflict {
+		for _, key := range keys {
+			if _, ok := e.meta[cfg.prefix+key]; ok {
+				return e, ErrMetaConflict.Str("key", cfg.prefix+key)
+			}
+		}
+	}
+
+	ne := e.mutable()
+	for _, key := range keys {
+		val := src[key]
+		key = cfg.prefix + key
+		old, ok := ne.meta[key]
+		switch {
+		case !ok:
+			ne.set(key, val)
+		case cfg.strategy == mergeKeep:
+		case cfg.strategy == mergeAppend:
+			ne.set(key, appendValue(old, val))
+		default:
+			ne.set(key, val)
+		}
+	}
+	return ne, nil
+}
@@ -0,0 +1,180 @@
+package zrr
+
+import (
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+)
+
+func Test_Error_MergeMeta(t *testing.T) {
+	t.Run("overwrite by default", func(t *testing.T) {
+		// --- Given ---
+		err := New("em0").Str("k0", "v0").Str("k1", "v1")
+		src := map[string]any{"k1": "s1", "k3": "s3", "k2": "s2"}
+
+		// --- When ---
+		got, e := err.MergeMeta(src)
+
+		// --- Then ---
+		assert.NoError(t, e)
+		assert.Same(t, err, got)
+		exp := map[string]any{"k0": "v0", "k1": "s1", "k2": "s2", "k3": "s3"}
+		assert.Equal(t, exp, got.GetMetadata())
+		assert.Equal(t, []string{"k0", "k1", "k2", "k3"}, got.keys)
+	})
+
+	t.Run("overwrite", func(t *testing.T) {
+		// --- Given ---
+		err := New("em0").Str("k0", "v0")
+
+		// --- When ---
+		got, e := err.MergeMeta(map[string]any{"k0": "s0"}, MergeKeep, MergeOverwrite)
+
+		// --- Then ---
+		assert.NoError(t, e)
+		assert.Equal(t, map[string]any{"k0": "s0"}, got.GetMetadata())
+	})
+
+	t.Run("keep", func(t *testing.T) {
+		// --- Given ---
+		err := New("em0").Str("k0", "v0")
+		src := map[string]any{"k0": "s0", "k1": "s1"}
+
+		// --- When ---
+		got, e := err.MergeMeta(src, MergeKeep)
+
+		// --- Then ---
+		assert.NoError(t, e)
+		exp := map[string]any{"k0": "v0", "k1": "s1"}
+		assert.Equal(t, exp, got.GetMetadata())
+	})
+
+	t.Run("append", func(t *testing.T) {
+		// --- Given ---
+		err := New("em0").Str("k0", "v0").with("k1", []any{1})
+		src := map[string]any{"k0": "s0", "k1": 2, "k2": "s2"}
+
+		// --- When ---
+		got, e := err.MergeMeta(src, MergeAppend)
+
+		// --- Then ---
+		assert.NoError(t, e)
+		exp := map[string]any{
+			"k0": []string{"v0", "s0"},
+			"k1": []any{1, 2},
+			"k2": "s2",
+		}
+		assert.Equal(t, exp, got.GetMetadata())
+	})
+
+	t.Run("conflict", func(t *testing.T) {
+		// --- Given ---
+		err := New("em0").Str("k1", "v1")
+		src := map[string]any{"k0": "s0", "k1": "s1"}
+
+		// --- When ---
+		got, e := err.MergeMeta(src, MergeConflict)
+
+		// --- Then ---
+		assert.ErrorIs(t, ErrMetaConflict, e)
+		assert.True(t, HasCode(e, ECMetaConflict))
+		key, _ := GetStr(e, "key")
+		assert.Equal(t, "k1", key)
+		assert.Same(t, err, got)
+		assert.Equal(t, map[string]any{"k1": "v1"}, got.GetMetadata())
+	})
+
+	t.Run("no conflict", func(t *testing.T) {
+		// --- Given ---
+		err := New("em0").Str("k0", "v0")
+
+		// --- When ---
+		got, e := err.MergeMeta(map[string]any{"k1": "s1"}, MergeConflict)
+
+		// --- Then ---
+		assert.NoError(t, e)
+		assert.Equal(t, map[string]any{"k0": "v0", "k1": "s1"}, got.GetMetadata())
+	})
+
+	t.Run("prefix", func(t *testing.T) {
+		// --- Given ---
+		err := New("em0").Str("db.k0", "v0")
+		src := map[string]any{"k0": "s0", "k1": "s1"}
+
+		// --- When ---
+		got, e := err.MergeMeta(src, MergePrefix("db."), MergeKeep)
+
+		// --- Then ---
+		assert.NoError(t, e)
+		exp := map[string]any{"db.k0": "v0", "db.k1": "s1"}
+		assert.Equal(t, exp, got.GetMetadata())
+	})
+
+	t.Run("prefix conflict", func(t *testing.T) {
+		// --- Given ---
+		err := New("em0").Str("db.k0", "v0")
+
+		// --- When ---
+		_, e := err.MergeMeta(map[string]any{"k0": 1}, MergePrefix("db."), MergeConflict)
+
+		// --- Then ---
+		key, _ := GetStr(e, "key")
+		assert.Equal(t, "db.k0", key)
+	})
+
+	t.Run("immutable single copy", func(t *testing.T) {
+		// --- Given ---
+		err := Imm("em0", "ECode")
+		src := map[string]any{"k0": "s0", "k1": "s1", "k2": "s2"}
+
+		// --- When ---
+		got, e := err.MergeMeta(src)
+
+		// --- Then ---
+		assert.NoError(t, e)
+		assert.NotSame(t, err, got)
+		assert.Same(t, err, got.Unwrap())
+		assert.Equal(t, src, got.GetMetadata())
+		assert.Len(t, 0, err.GetMetadata())
+	})
+
+	t.Run("immutable empty source", func(t *testing.T) {
+		// --- Given ---
+		err := Imm("em0", "ECode")
+
+		// --- When ---
+		got, e := err.MergeMeta(nil)
+
+		// --- Then ---
+		assert.NoError(t, e)
+		assert.Same(t, err, got)
+	})
+}
+
+func Test_Error_MergeFrom(t *testing.T) {
+	t.Run("error keeps source order", func(t *testing.T) {
+		// --- Given ---
+		err := New("em0").Str("k0", "v0")
+		src := New("em1").Str("z", "1").Str("a", "2")
+
+		// --- When ---
+		got, e := err.MergeFrom(src)
+
+		// --- Then ---
+		assert.NoError(t, e)
+		assert.Equal(t, []string{"k0", "z", "a"}, got.keys)
+	})
+
+	t.Run("metadata getter", func(t *testing.T) {
+		// --- Given ---
+		err := New("em0").Str("k0", "v0")
+		src := implementor{meta: map[string]any{"z": 1, "k0": 2}}
+
+		// --- When ---
+		got, e := err.MergeFrom(src, MergeKeep)
+
+		// --- Then ---
+		assert.NoError(t, e)
+		assert.Equal(t, map[string]any{"k0": "v0", "z": 1}, got.GetMetadata())
+	})
+}
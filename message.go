@@ -0,0 +1,110 @@
+package zrr
+
+import (
+	"fmt"
+	"iter"
+	"maps"
+	"strings"
+)
+
+// MsgTemplate returns the error message before placeholders are rendered.
+// Messages with the same template are instances of the same error, which
+// makes the template useful for grouping.
+func (e *Error) MsgTemplate() string {
+	if e.msg != "" {
+		return e.msg + ": " + rawMsg(e.error)
+	}
+	return rawMsg(e.error)
+}
+
+// rawMsg returns the message of err before placeholders are rendered.
+func rawMsg(err error) string {
+	if e, ok := err.(*Error); ok && e != nil {
+		return e.MsgTemplate()
+	}
+	return err.Error()
+}
+
+// message returns the error message with templates in the chain of
+// e rendered with metadata values from the chain of root (see AllFields).
+func (e *Error) message(root *Error) string {
+	if e.msg != "" {
+		return e.msg + ": " + e.cause(root)
+	}
+	return e.cause(root)
+}
+
+// cause returns the message of the wrapped error. Only the messages given
+// to New and Imm are templates, other messages are returned as is.
+func (e *Error) cause(root *Error) string {
+	if c, ok := e.error.(*Error); ok && c != nil {
+		return c.message(root)
+	}
+	if e.tpl {
+		return renderMsg(e.error.Error(), AllFields(root))
+	}
+	return e.error.Error()
+}
+
+// renderMsg replaces "{key}" placeholders in msg with values from fields.
+// Placeholders without matching keys are left as is, sensitive values are
+// masked and values longer than MaxStrLen limit are truncated (see
+// SetLimits). The fields are iterated only when msg has placeholders.
+func renderMsg(msg string, fields iter.Seq2[string, any]) string {
+	if !strings.Contains(msg, "{") {
+		return msg
+	}
+	var meta map[string]any
+	var sb strings.Builder
+	maxLen := GetLimits().MaxStrLen
+	for {
+		start := strings.IndexByte(msg, '{')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(msg[start:], '}')
+		if end < 0 {
+			break
+		}
+		end += start
+		key := msg[start+1 : end]
+		if !isPlaceholder(key) {
+			sb.WriteString(msg[:start+1])
+			msg = msg[start+1:]
+			continue
+		}
+		if meta == nil {
+			meta = maps.Collect(fields)
+		}
+		sb.WriteString(msg[:start])
+		if val, ok := meta[key]; ok {
+			val := fmt.Sprint(Redact(key, val))
+			if maxLen > 0 {
+				val = truncate(val, maxLen)
+			}
+			sb.WriteString(val)
+		} else {
+			sb.WriteString(msg[start : end+1])
+		}
+		msg = msg[end+1:]
+	}
+	sb.WriteString(msg)
+	return sb.String()
+}
+
+// isPlaceholder returns true if key is a valid placeholder name. Valid names
+// are not empty and consist of letters, digits, underscores, dashes and dots.
+func isPlaceholder(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, r := range key {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '_', r == '-', r == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
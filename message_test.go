@@ -0,0 +1,216 @@
+package zrr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+)
+
+func Test_Error_Error_placeholders(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		exp string
+		err *Error
+	}{
+		{"no placeholders", "em0", New("em0").Int("user_id", 42)},
+		{
+			"rendered",
+			"user 42 not found in eu",
+			New("user {user_id} not found in {region}").
+				Int("user_id", 42).Str("region", "eu"),
+		},
+		{
+			"missing key",
+			"user {user_id} not found",
+			New("user {user_id} not found"),
+		},
+		{
+			"not a placeholder",
+			`bad {"user id"} {} { user_id } 42`,
+			New(`bad {"user id"} {} { user_id } {user_id}`).Int("user_id", 42),
+		},
+		{
+			"unclosed",
+			"user 42 {user_id",
+			New("user {user_id} {user_id").Int("user_id", 42),
+		},
+		{"repeated", "42 42", New("{user_id} {user_id}").Int("user_id", 42)},
+		{"dotted key", "a b", New("{a.b} {c-d}").Str("a.b", "a").Str("c-d", "b")},
+		{
+			"message prefix is literal",
+			"get {id}: user 42 not found",
+			WrapMsg(New("user {id} not found").Int("id", 42), "get {id}"),
+		},
+		{
+			"immutable sentinel",
+			"user 42 not found",
+			Imm("user {id} not found").Int("id", 42),
+		},
+		{
+			"wrapped immutable copy",
+			"get: user 42 not found",
+			WrapMsg(Imm("user {id} not found"), "get").Int("id", 42),
+		},
+		{
+			"std error wrapping template is literal",
+			"w: user {id} not found",
+			Wrap(fmt.Errorf("w: %w", Imm("user {id} not found"))).Int("id", 42),
+		},
+		{
+			"std error wrapping template with own metadata",
+			"w: user 42 not found",
+			Wrap(fmt.Errorf("w: %w", Imm("user {id} not found").Int("id", 42))),
+		},
+		{
+			"scope",
+			"user 42 not found",
+			Scope("billing").New("user {id} not found").Int("id", 42),
+		},
+		{
+			"list value",
+			"ids [1 2]",
+			New("ids {ids}").AppendInt("ids", 1).AppendInt("ids", 2),
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			assert.Equal(t, tc.exp, tc.err.Error())
+		})
+	}
+}
+
+func Test_Error_Error_placeholderInjection(t *testing.T) {
+	t.Run("Newf", func(t *testing.T) {
+		// --- Given ---
+		err := Newf("bad input %q", "{secret_key}").Str("secret_key", "S3CR3T")
+
+		// --- When ---
+		have := err.Error()
+
+		// --- Then ---
+		assert.Equal(t, `bad input "{secret_key}"`, have)
+	})
+
+	t.Run("Wrapf", func(t *testing.T) {
+		// --- Given ---
+		err := Wrapf(New("em0"), "input %s", "{secret_key}").
+			Str("secret_key", "S3CR3T")
+
+		// --- When ---
+		have := err.Error()
+
+		// --- Then ---
+		assert.Equal(t, "input {secret_key}: em0", have)
+	})
+
+	t.Run("wrapped std error", func(t *testing.T) {
+		// --- Given ---
+		err := Wrap(errors.New("bad input {secret_key}")).
+			Str("secret_key", "S3CR3T")
+
+		// --- When ---
+		have := err.Error()
+
+		// --- Then ---
+		assert.Equal(t, "bad input {secret_key}", have)
+		assert.Equal(t, "bad input {secret_key}", err.CauseMsg())
+	})
+
+	t.Run("metadata values are not rendered", func(t *testing.T) {
+		// --- Given ---
+		err := New("bad input {input}").
+			Str("input", "{secret_key}").
+			Str("secret_key", "S3CR3T")
+
+		// --- When ---
+		have := err.Error()
+
+		// --- Then ---
+		assert.Equal(t, "bad input {secret_key}", have)
+	})
+}
+
+func Test_Error_MsgTemplate(t *testing.T) {
+	t.Run("not rendered", func(t *testing.T) {
+		// --- Given ---
+		sentinel := Imm("user {user_id} not found")
+		err := sentinel.Int("user_id", 42)
+
+		// --- When ---
+		have := err.MsgTemplate()
+
+		// --- Then ---
+		assert.Equal(t, "user {user_id} not found", have)
+		assert.Equal(t, "user 42 not found", err.Error())
+		assert.True(t, errors.Is(err, sentinel))
+	})
+
+	t.Run("with prefix", func(t *testing.T) {
+		// --- Given ---
+		err := WrapMsg(Imm("user {id} not found").Int("id", 42), "get")
+
+		// --- When ---
+		have := err.MsgTemplate()
+
+		// --- Then ---
+		assert.Equal(t, "get: user {id} not found", have)
+	})
+
+	t.Run("std error", func(t *testing.T) {
+		// --- Given ---
+		err := Wrap(errors.New("em0"))
+
+		// --- When ---
+		have := err.MsgTemplate()
+
+		// --- Then ---
+		assert.Equal(t, "em0", have)
+	})
+}
+
+func Test_Error_CauseMsg_placeholders(t *testing.T) {
+	// --- Given ---
+	err := WrapMsg(New("user {id} not found"), "get").Int("id", 42)
+
+	// --- When ---
+	have := err.CauseMsg()
+
+	// --- Then ---
+	assert.Equal(t, "user 42 not found", have)
+}
+
+func Test_Error_Format_placeholders(t *testing.T) {
+	// --- Given ---
+	err := Imm("user {id} not found", "ECode").Int("id", 42)
+
+	// --- Then ---
+	assert.Equal(t, "user 42 not found", fmt.Sprintf("%v", err))
+	assert.Equal(t, `"user 42 not found"`, fmt.Sprintf("%q", err))
+	assert.Equal(t, "user 42 not found [ECode] id=42", fmt.Sprintf("%+v", err))
+}
+
+func Test_Error_MarshalJSON_placeholders(t *testing.T) {
+	// --- Given ---
+	err := Imm("user {id} not found", "ECode").Int("id", 42)
+
+	// --- When ---
+	data, jErr := json.Marshal(err)
+
+	// --- Then ---
+	assert.NoError(t, jErr)
+	want := `{"error":"user 42 not found","code":"ECode","meta":{"id":42}}`
+	assert.JSON(t, want, string(data))
+}
+
+func Test_isPlaceholder(t *testing.T) {
+	assert.True(t, isPlaceholder("user_id"))
+	assert.True(t, isPlaceholder("a.b-C9"))
+	assert.False(t, isPlaceholder(""))
+	assert.False(t, isPlaceholder("user id"))
+	assert.False(t, isPlaceholder(`"id"`))
+}
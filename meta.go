@@ -0,0 +1,191 @@
+package zrr
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"sync/atomic"
+)
+
+// MetaOrder represents the order in which error metadata keys are iterated
+// and serialized.
+type MetaOrder int32
+
+const (
+	// OrderInsertion iterates metadata keys in the order they were added.
+	OrderInsertion MetaOrder = iota
+
+	// OrderSorted iterates metadata keys in lexicographical order.
+	OrderSorted
+)
+
+// metaOrder is the package wide metadata order.
+var metaOrder atomic.Int32
+
+// SetMetaOrder sets the package wide order in which metadata keys are
+// iterated and serialized. By default, keys are in insertion order.
+func SetMetaOrder(o MetaOrder) { metaOrder.Store(int32(o)) }
+
+// GetMetaOrder returns the package wide metadata order.
+func GetMetaOrder() MetaOrder { return MetaOrder(metaOrder.Load()) }
+
+// set sets the metadata key to value v keeping track of the insertion order.
+func (e *Error) set(key string, v any) {
+	if _, ok := e.meta[key]; !ok {
+		e.keys = append(e.keys, key)
+	}
+	e.meta[key] = v
+}
+
+// get returns the metadata key value. Sensitive values are returned
+// unmasked.
+func (e *Error) get(key string) (any, bool) {
+	val, ok := e.meta[key]
+	return Reveal(val), ok
+}
+
+// del deletes the metadata key.
+func (e *Error) del(key string) {
+	if _, ok := e.meta[key]; !ok {
+		return
+	}
+	delete(e.meta, key)
+	e.keys = slices.DeleteFunc(e.keys, func(k string) bool { return k == key })
+}
+
+// mutable returns e when it's mutable, otherwise it returns a new mutable
+// instance wrapping e with a copy of its metadata. Because of the copy, the
+// metadata of an immutable instance is never merged with the metadata of its
+// copy (see metaLayers).
+func (e *Error) mutable() *Error {
+	if !e.imm {
+		return e
+	}
+	ne := base(e, false, e.code)
+	ne.cp = true
+	for _, key := range e.keys {
+		ne.set(key, copyValue(e.meta[key]))
+	}
+	return ne
+}
+
+// copyValue returns a deep copy of slices and maps, other values are
+// returned as is.
+func copyValue(v any) any {
+	if v == nil {
+		return nil
+	}
+	return copyReflect(reflect.ValueOf(v)).Interface()
+}
+
+// copyReflect returns a deep copy of slices and maps, other values are
+// returned as is.
+func copyReflect(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		cp := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			cp.Index(i).Set(copyReflect(v.Index(i)))
+		}
+		return cp
+
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		cp := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			cp.SetMapIndex(iter.Key(), copyReflect(iter.Value()))
+		}
+		return cp
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		cp := reflect.New(v.Type()).Elem()
+		cp.Set(copyReflect(v.Elem()))
+		return cp
+
+	default:
+		return v
+	}
+}
+
+// metaKeys returns metadata keys in the order set with SetMetaOrder. The
+// returned slice should be considered read-only.
+func (e *Error) metaKeys() []string {
+	if GetMetaOrder() == OrderSorted {
+		keys := slices.Clone(e.keys)
+		slices.Sort(keys)
+		return keys
+	}
+	return e.keys
+}
+
+// marshalMeta writes metadata key value pairs as a JSON object to buf. Keys
+// are written in the order they are in the keys slice, sensitive values are
+// masked and the limits are applied. The size is the number of bytes
+// already counted against MaxSize limit (see outFields).
+func marshalMeta(buf *bytes.Buffer, keys []string, meta map[string]any, size int) error {
+	buf.WriteByte('{')
+	for i, f := range outFields(keys, meta, size) {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		if err := marshalValue(buf, f.key); err != nil {
+			return err
+		}
+		buf.WriteByte(':')
+		if err := marshalValue(buf, f.val); err != nil {
+			return err
+		}
+	}
+	buf.WriteByte('}')
+	return nil
+}
+
+// marshalValue writes JSON representation of v to buf.
+func marshalValue(buf *bytes.Buffer, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	buf.Write(data)
+	return nil
+}
+
+// unmarshalMeta decodes JSON object preserving the order of its keys. It
+// returns empty metadata when data does not represent a JSON object.
+func unmarshalMeta(data []byte) ([]string, map[string]any, error) {
+	keys := make([]string, 0)
+	meta := make(map[string]any)
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	tok, err := dec.Token()
+	if err != nil || tok != json.Delim('{') {
+		return keys, meta, nil
+	}
+
+	for dec.More() {
+		if tok, err = dec.Token(); err != nil {
+			return nil, nil, err
+		}
+		key, _ := tok.(string)
+
+		var val any
+		if err = dec.Decode(&val); err != nil {
+			return nil, nil, err
+		}
+		if _, ok := meta[key]; !ok {
+			keys = append(keys, key)
+		}
+		meta[key] = val
+	}
+	return keys, meta, nil
+}
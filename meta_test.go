@@ -0,0 +1,190 @@
+package zrr
+
+import (
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+)
+
+func Test_SetMetaOrder(t *testing.T) {
+	t.Cleanup(func() { SetMetaOrder(OrderInsertion) })
+
+	// --- When ---
+	SetMetaOrder(OrderSorted)
+
+	// --- Then ---
+	assert.Equal(t, OrderSorted, GetMetaOrder())
+}
+
+func Test_GetMetaOrder_default(t *testing.T) {
+	// --- When ---
+	got := GetMetaOrder()
+
+	// --- Then ---
+	assert.Equal(t, OrderInsertion, got)
+}
+
+func Test_Error_metaKeys(t *testing.T) {
+	t.Run("insertion order", func(t *testing.T) {
+		// --- Given ---
+		err := New("em0").Str("b", "1").Str("c", "2").Str("a", "3")
+
+		// --- When ---
+		got := err.metaKeys()
+
+		// --- Then ---
+		assert.Equal(t, []string{"b", "c", "a"}, got)
+	})
+
+	t.Run("sorted order", func(t *testing.T) {
+		// --- Given ---
+		SetMetaOrder(OrderSorted)
+		t.Cleanup(func() { SetMetaOrder(OrderInsertion) })
+		err := New("em0").Str("b", "1").Str("c", "2").Str("a", "3")
+
+		// --- When ---
+		got := err.metaKeys()
+
+		// --- Then ---
+		assert.Equal(t, []string{"a", "b", "c"}, got)
+		assert.Equal(t, []string{"b", "c", "a"}, err.keys)
+	})
+
+	t.Run("overwritten key keeps position", func(t *testing.T) {
+		// --- Given ---
+		err := New("em0").Str("b", "1").Str("a", "2").Str("b", "3")
+
+		// --- When ---
+		got := err.metaKeys()
+
+		// --- Then ---
+		assert.Equal(t, []string{"b", "a"}, got)
+		assert.Equal(t, "3", err.meta["b"])
+	})
+}
+
+func Test_unmarshalMeta(t *testing.T) {
+	t.Run("object", func(t *testing.T) {
+		// --- Given ---
+		data := []byte(`{"z": 1, "a": "b", "m": [1, 2]}`)
+
+		// --- When ---
+		keys, meta, err := unmarshalMeta(data)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"z", "a", "m"}, keys)
+		exp := map[string]any{
+			"z": float64(1),
+			"a": "b",
+			"m": []any{float64(1), float64(2)},
+		}
+		assert.Equal(t, exp, meta)
+	})
+
+	t.Run("not an object", func(t *testing.T) {
+		// --- Given ---
+		data := []byte(`[1, 2]`)
+
+		// --- When ---
+		keys, meta, err := unmarshalMeta(data)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Len(t, 0, keys)
+		assert.Len(t, 0, meta)
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		// --- When ---
+		keys, meta, err := unmarshalMeta(nil)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.NotNil(t, keys)
+		assert.NotNil(t, meta)
+	})
+}
+
+func Test_Error_del(t *testing.T) {
+	// --- Given ---
+	err := New("em0").Str("key0", "val0").Int("key1", 1).Int("key2", 2)
+
+	// --- When ---
+	err.del("key1")
+	err.del("keyX")
+
+	// --- Then ---
+	assert.Equal(t, map[string]any{"key0": "val0", "key2": 2}, err.meta)
+	assert.Equal(t, []string{"key0", "key2"}, err.keys)
+}
+
+func Test_Error_mutable(t *testing.T) {
+	t.Run("mutable", func(t *testing.T) {
+		// --- Given ---
+		err := New("em0")
+
+		// --- When ---
+		got := err.mutable()
+
+		// --- Then ---
+		assert.Same(t, err, got)
+	})
+
+	t.Run("immutable", func(t *testing.T) {
+		// --- Given ---
+		err := Imm("em0", "ECode")
+		err.set("key0", []int{1})
+
+		// --- When ---
+		got := err.mutable()
+
+		// --- Then ---
+		assert.NotSame(t, err, got)
+		assert.False(t, got.imm)
+		assert.Same(t, err, got.Unwrap())
+		assert.Equal(t, "ECode", got.code)
+		assert.Equal(t, err.meta, got.meta)
+		got.meta["key0"].([]int)[0] = 2
+		assert.Equal(t, []int{1}, err.meta["key0"])
+	})
+}
+
+func Test_copyValue(t *testing.T) {
+	t.Run("scalar", func(t *testing.T) {
+		assert.Equal(t, 1, copyValue(1))
+		assert.Equal(t, "a", copyValue("a"))
+		assert.Nil(t, copyValue(nil))
+	})
+
+	t.Run("slice", func(t *testing.T) {
+		// --- Given ---
+		val := []any{1, []string{"a"}}
+
+		// --- When ---
+		got := copyValue(val).([]any)
+
+		// --- Then ---
+		assert.Equal(t, val, got)
+		got[1].([]string)[0] = "b"
+		assert.Equal(t, []string{"a"}, val[1])
+	})
+
+	t.Run("map", func(t *testing.T) {
+		// --- Given ---
+		val := map[string]any{"a": []int{1}}
+
+		// --- When ---
+		got := copyValue(val).(map[string]any)
+
+		// --- Then ---
+		assert.Equal(t, val, got)
+		got["a"].([]int)[0] = 2
+		assert.Equal(t, []int{1}, val["a"])
+	})
+
+	t.Run("nil slice and map", func(t *testing.T) {
+		assert.Nil(t, copyValue([]string(nil)))
+		assert.Nil(t, copyValue(map[string]any(nil)))
+	})
+}
@@ -0,0 +1,106 @@
+package zrr
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// ECPanic represents recovered panic error code.
+const ECPanic = "ECPanic"
+
+// KeyStack is the metadata key for the stack trace of the recovered panic.
+const KeyStack = "stack"
+
+// FromPanic converts the recovered panic value v to Error instance with
+// ECPanic code and the stack trace of the current goroutine set as a list
+// of strings under KeyStack key. When v is an error it's wrapped, so
+// errors.Is and errors.As work with it. Other values are available with
+// GetPanic. The hooks, if provided, are called in order with the created
+// error and may add metadata to it. It returns nil if v is nil.
+func FromPanic(v any, hooks ...func(*Error) *Error) *Error {
+	return fromPanic(v, 1, hooks...)
+}
+
+// Recover is meant to be deferred, it recovers from panic and sets err to
+// Error instance created with FromPanic. When err already holds an error
+// both errors are joined with errors.Join.
+//
+//	func worker() (err error) {
+//	    defer zrr.Recover(&err)
+//	    ...
+//	}
+func Recover(err *error, hooks ...func(*Error) *Error) {
+	v := recover()
+	if v == nil {
+		return
+	}
+	e := fromPanic(v, 1, hooks...)
+	if !isNil(*err) {
+		*err = errors.Join(*err, e)
+		return
+	}
+	*err = e
+}
+
+// GetPanic returns the recovered panic value if err was created with
+// FromPanic or Recover.
+func GetPanic(err error) (any, bool) {
+	if isNil(err) {
+		return nil, false
+	}
+	var pv *panicValue
+	if errors.As(err, &pv) {
+		return pv.v, true
+	}
+	return nil, false
+}
+
+// fromPanic converts the recovered panic value to Error instance. The skip
+// is the number of fromPanic callers to skip when collecting the stack trace.
+func fromPanic(v any, skip int, hooks ...func(*Error) *Error) *Error {
+	if v == nil {
+		return nil
+	}
+	e := base(&panicValue{v: v}, false, ECPanic)
+	e.msg = "panic"
+	e.set(KeyStack, stack(skip+2))
+	for _, hook := range hooks {
+		e = hook(e)
+	}
+	return e
+}
+
+// panicValue represents recovered panic value.
+type panicValue struct{ v any }
+
+// Error returns the panic value formatted with fmt.Sprint.
+func (p *panicValue) Error() string { return fmt.Sprint(p.v) }
+
+// Unwrap returns the panic value if it's an error, otherwise it returns nil.
+func (p *panicValue) Unwrap() error {
+	err, _ := p.v.(error)
+	return err
+}
+
+// stack returns the stack trace of the current goroutine skipping runtime
+// frames. The skip is the number of frames to skip, with 1 identifying the
+// stack function itself. Each frame is in form "function file:line".
+func stack(skip int) []string {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(skip+1, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var trace []string
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "runtime.") {
+			trace = append(trace, fmt.Sprintf("%s %s:%d", frame.Function, frame.File, frame.Line))
+		}
+		if !more {
+			break
+		}
+	}
+	return trace
+}
@@ -0,0 +1,203 @@
+package zrr
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+)
+
+func Test_FromPanic(t *testing.T) {
+	t.Run("error", func(t *testing.T) {
+		// --- Given ---
+		e := errors.New("boom")
+
+		// --- When ---
+		err := FromPanic(e)
+
+		// --- Then ---
+		assert.Equal(t, "panic: boom", err.Error())
+		assert.Equal(t, "boom", err.CauseMsg())
+		assert.Equal(t, ECPanic, err.ErrCode())
+		assert.ErrorIs(t, e, err)
+		v, ok := GetPanic(err)
+		assert.True(t, ok)
+		assert.Same(t, e, v)
+	})
+
+	t.Run("zrr error", func(t *testing.T) {
+		// --- Given ---
+		e := New("boom", "ECBoom").Str("key0", "val0")
+
+		// --- When ---
+		err := FromPanic(e)
+
+		// --- Then ---
+		assert.Equal(t, ECPanic, err.ErrCode())
+		assert.ErrorIs(t, e, err)
+		var ze *Error
+		assert.True(t, errors.As(err.Unwrap(), &ze))
+		assert.Same(t, e, ze)
+	})
+
+	t.Run("not error", func(t *testing.T) {
+		// --- When ---
+		err := FromPanic(42)
+
+		// --- Then ---
+		assert.Equal(t, "panic: 42", err.Error())
+		assert.Equal(t, ECPanic, err.ErrCode())
+		v, ok := GetPanic(err)
+		assert.True(t, ok)
+		assert.Equal(t, 42, v)
+	})
+
+	t.Run("stack", func(t *testing.T) {
+		// --- When ---
+		err := FromPanic("boom")
+
+		// --- Then ---
+		trace, ok := GetStrs(err, KeyStack)
+		assert.True(t, ok)
+		assert.True(t, len(trace) > 0)
+		assert.True(t, strings.HasPrefix(trace[0], "github.com/rzajac/zrr.Test_FromPanic"))
+		assert.Contain(t, "panic_test.go:", trace[0])
+	})
+
+	t.Run("hooks", func(t *testing.T) {
+		// --- Given ---
+		hook0 := func(e *Error) *Error { return e.Str("worker", "w1") }
+		hook1 := func(e *Error) *Error { return e.Int("job", 2) }
+
+		// --- When ---
+		err := FromPanic("boom", hook0, hook1)
+
+		// --- Then ---
+		worker, _ := GetStr(err, "worker")
+		assert.Equal(t, "w1", worker)
+		job, _ := GetInt(err, "job")
+		assert.Equal(t, 2, job)
+		assert.Equal(t, []string{KeyStack, "worker", "job"}, err.keys)
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		// --- When ---
+		err := FromPanic(nil)
+
+		// --- Then ---
+		assert.Nil(t, err)
+	})
+}
+
+// panicking panics with v recovering with Recover.
+func panicking(v any, hooks ...func(*Error) *Error) (err error) {
+	defer Recover(&err, hooks...)
+	panic(v)
+}
+
+func Test_Recover(t *testing.T) {
+	t.Run("panic", func(t *testing.T) {
+		// --- When ---
+		err := panicking("boom")
+
+		// --- Then ---
+		assert.ErrorEqual(t, "panic: boom", err)
+		assert.True(t, HasCode(err, ECPanic))
+		trace, _ := GetStrs(err, KeyStack)
+		assert.True(t, len(trace) > 0)
+		assert.True(t, strings.HasPrefix(trace[0], "github.com/rzajac/zrr.panicking"))
+	})
+
+	t.Run("panic with error", func(t *testing.T) {
+		// --- Given ---
+		e := fmt.Errorf("boom")
+
+		// --- When ---
+		err := panicking(e)
+
+		// --- Then ---
+		assert.ErrorIs(t, e, err)
+	})
+
+	t.Run("hooks", func(t *testing.T) {
+		// --- When ---
+		err := panicking("boom", func(e *Error) *Error { return e.Str("k", "v") })
+
+		// --- Then ---
+		v, _ := GetStr(err, "k")
+		assert.Equal(t, "v", v)
+	})
+
+	t.Run("existing error", func(t *testing.T) {
+		// --- Given ---
+		e := errors.New("first")
+		fn := func() (err error) {
+			defer Recover(&err)
+			err = e
+			panic("boom")
+		}
+
+		// --- When ---
+		err := fn()
+
+		// --- Then ---
+		assert.ErrorIs(t, e, err)
+		assert.True(t, HasCode(err, ECPanic))
+		assert.ErrorEqual(t, "first\npanic: boom", err)
+	})
+
+	t.Run("typed nil error", func(t *testing.T) {
+		// --- Given ---
+		fn := func() (err error) {
+			defer Recover(&err)
+			var ne *nilErr
+			err = ne
+			panic("boom")
+		}
+
+		// --- When ---
+		err := fn()
+
+		// --- Then ---
+		assert.ErrorEqual(t, "panic: boom", err)
+	})
+
+	t.Run("no panic", func(t *testing.T) {
+		// --- Given ---
+		fn := func() (err error) {
+			defer Recover(&err)
+			return nil
+		}
+
+		// --- When ---
+		err := fn()
+
+		// --- Then ---
+		assert.NoError(t, err)
+	})
+}
+
+func Test_GetPanic(t *testing.T) {
+	t.Run("wrapped", func(t *testing.T) {
+		// --- Given ---
+		err := fmt.Errorf("wrap: %w", FromPanic("boom"))
+
+		// --- When ---
+		v, ok := GetPanic(err)
+
+		// --- Then ---
+		assert.True(t, ok)
+		assert.Equal(t, "boom", v)
+	})
+
+	t.Run("not panic", func(t *testing.T) {
+		// --- When ---
+		v, ok := GetPanic(New("em0"))
+
+		// --- Then ---
+		assert.False(t, ok)
+		assert.Nil(t, v)
+	})
+}
@@ -0,0 +1,113 @@
+package zrr
+
+import (
+	"bytes"
+	"iter"
+	"maps"
+	"slices"
+)
+
+// PublicMsgDefault is the public message of errors without public message
+// set with SetPublic or registered for their code.
+const PublicMsgDefault = "internal error"
+
+// SetPublic sets the error message which is safe to show to API clients and
+// the metadata keys which may be shown with it. The public message may have
+// "{key}" placeholders, only the public keys are used to render them. When
+// the error is immutable the public message is set on its mutable copy.
+func (e *Error) SetPublic(msg string, keys ...string) *Error {
+	ne := e.mutable()
+	ne.pub = msg
+	ne.pubKeys = slices.Clone(keys)
+	return ne
+}
+
+// PublicView represents error as seen by API clients.
+type PublicView struct {
+	Message string         // Public message.
+	Code    string         // Error code.
+	Meta    map[string]any // Public metadata.
+	keys    []string       // Public metadata keys in order.
+}
+
+// Public returns public view of err. The err chain is walked and the first
+// Error instance with public message set with SetPublic or with a code
+// registered with public message decides the message and public metadata
+// keys. The metadata values are taken from the whole chain (see AllFields).
+// When err has no public message PublicMsgDefault is used.
+//
+// Transports (HTTP handlers, gRPC interceptors) should always use the public
+// view, the error message is meant for logs.
+func Public(err error) PublicView {
+	view := PublicView{Message: PublicMsgDefault, Meta: make(map[string]any)}
+	if isNil(err) {
+		return view
+	}
+	view.Code = GetCode(err)
+
+	var keys []string
+	for e := range Chain(err) {
+		if e.pub != "" {
+			view.Message, keys = e.pub, e.pubKeys
+			break
+		}
+		if info, ok := Lookup(e.code); ok && info.Public != "" {
+			view.Message, keys = info.Public, info.PublicKeys
+			break
+		}
+	}
+	if len(keys) > 0 {
+		for key, val := range AllFields(err) {
+			if slices.Contains(keys, key) {
+				view.keys = append(view.keys, key)
+				view.Meta[key] = val
+			}
+		}
+	}
+	view.Message = renderMsg(view.Message, view.Fields())
+	return view
+}
+
+// Fields returns an iterator over public metadata key value pairs. The keys
+// are iterated in the order they were found in the error chain or sorted
+// when the view was not created with Public.
+func (v PublicView) Fields() iter.Seq2[string, any] {
+	return func(yield func(string, any) bool) {
+		for _, key := range v.metaKeys() {
+			if !yield(key, v.Meta[key]) {
+				return
+			}
+		}
+	}
+}
+
+// MarshalJSON implements json.Marshaler interface. The view is serialized in
+// the same format as Error.
+func (v PublicView) MarshalJSON() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	buf.WriteString(`{"error":`)
+	msg, size := outMsg(v.Message)
+	if err := marshalValue(buf, msg); err != nil {
+		return nil, err
+	}
+	buf.WriteString(`,"code":`)
+	if err := marshalValue(buf, v.Code); err != nil {
+		return nil, err
+	}
+	if len(v.Meta) > 0 {
+		buf.WriteString(`,"meta":`)
+		if err := marshalMeta(buf, v.metaKeys(), v.Meta, size); err != nil {
+			return nil, err
+		}
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// metaKeys returns public metadata keys in order.
+func (v PublicView) metaKeys() []string {
+	if len(v.keys) == len(v.Meta) {
+		return v.keys
+	}
+	return slices.Sorted(maps.Keys(v.Meta))
+}
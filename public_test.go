@@ -0,0 +1,191 @@
+package zrr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+)
+
+func Test_Error_SetPublic(t *testing.T) {
+	t.Run("mutable", func(t *testing.T) {
+		// --- Given ---
+		err := New("pq: relation users does not exist")
+		keys := []string{"user_id"}
+
+		// --- When ---
+		have := err.SetPublic("user not found", keys...)
+		keys[0] = "changed"
+
+		// --- Then ---
+		assert.Same(t, err, have)
+		assert.Equal(t, "user not found", have.pub)
+		assert.Equal(t, []string{"user_id"}, have.pubKeys)
+		assert.Equal(t, "pq: relation users does not exist", have.Error())
+	})
+
+	t.Run("immutable", func(t *testing.T) {
+		// --- Given ---
+		err := Imm("em0")
+
+		// --- When ---
+		have := err.SetPublic("public")
+
+		// --- Then ---
+		assert.NotSame(t, err, have)
+		assert.Equal(t, "public", have.pub)
+		assert.Equal(t, "", err.pub)
+	})
+}
+
+func Test_Public(t *testing.T) {
+	t.Run("public message and keys", func(t *testing.T) {
+		// --- Given ---
+		err := New("pq: relation users does not exist", "ECUserNotFound").
+			Int("user_id", 42).
+			Str("query", "SELECT * FROM users").
+			SetPublic("user {user_id} not found ({query})", "user_id")
+
+		// --- When ---
+		have := Public(err)
+
+		// --- Then ---
+		assert.Equal(t, "user 42 not found ({query})", have.Message)
+		assert.Equal(t, "ECUserNotFound", have.Code)
+		assert.Equal(t, map[string]any{"user_id": 42}, have.Meta)
+	})
+
+	t.Run("default message", func(t *testing.T) {
+		// --- Given ---
+		err := New("pq: relation users does not exist", "ECode").Int("id", 1)
+
+		// --- When ---
+		have := Public(err)
+
+		// --- Then ---
+		assert.Equal(t, PublicMsgDefault, have.Message)
+		assert.Equal(t, "ECode", have.Code)
+		assert.Equal(t, map[string]any{}, have.Meta)
+	})
+
+	t.Run("registered", func(t *testing.T) {
+		// --- Given ---
+		resetRegistry(t)
+		Register(CodeInfo{
+			Code:       "ECUserNotFound",
+			Public:     "user not found",
+			PublicKeys: []string{"user_id"},
+		})
+		e0 := New("pq: no rows", "ECUserNotFound").Int("user_id", 42)
+		err := fmt.Errorf("w: %w", e0)
+
+		// --- When ---
+		have := Public(err)
+
+		// --- Then ---
+		assert.Equal(t, "user not found", have.Message)
+		assert.Equal(t, map[string]any{"user_id": 42}, have.Meta)
+	})
+
+	t.Run("set overrides registered", func(t *testing.T) {
+		// --- Given ---
+		resetRegistry(t)
+		Register(CodeInfo{Code: "ECUserNotFound", Public: "user not found"})
+		err := New("em0", "ECUserNotFound").SetPublic("no such user")
+
+		// --- When ---
+		have := Public(err)
+
+		// --- Then ---
+		assert.Equal(t, "no such user", have.Message)
+	})
+
+	t.Run("closest to err wins", func(t *testing.T) {
+		// --- Given ---
+		e0 := New("em0").SetPublic("inner")
+		err := Wrap(fmt.Errorf("w: %w", e0)).SetPublic("outer")
+
+		// --- When ---
+		have := Public(err)
+
+		// --- Then ---
+		assert.Equal(t, "outer", have.Message)
+	})
+
+	t.Run("public keys from the whole chain", func(t *testing.T) {
+		// --- Given ---
+		e0 := New("em0").Int("user_id", 42).SetPublic("user not found", "user_id", "region")
+		err := Wrap(fmt.Errorf("w: %w", e0)).Str("region", "eu").Str("host", "db1")
+
+		// --- When ---
+		have := Public(err)
+
+		// --- Then ---
+		want := map[string]any{"user_id": 42, "region": "eu"}
+		assert.Equal(t, want, have.Meta)
+	})
+
+	t.Run("std error", func(t *testing.T) {
+		// --- When ---
+		have := Public(errors.New("pq: relation users does not exist"))
+
+		// --- Then ---
+		assert.Equal(t, PublicMsgDefault, have.Message)
+		assert.Equal(t, "", have.Code)
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		// --- When ---
+		have := Public(nil)
+
+		// --- Then ---
+		assert.Equal(t, PublicMsgDefault, have.Message)
+	})
+}
+
+func Test_PublicView_MarshalJSON(t *testing.T) {
+	t.Run("ordered meta", func(t *testing.T) {
+		// --- Given ---
+		err := New("em0", "ECode").
+			Int("b", 2).Int("a", 1).Int("c", 3).
+			SetPublic("public", "b", "a")
+
+		// --- When ---
+		data, jErr := json.Marshal(Public(err))
+
+		// --- Then ---
+		assert.NoError(t, jErr)
+		want := `{"error":"public","code":"ECode","meta":{"b":2,"a":1}}`
+		assert.Equal(t, want, string(data))
+	})
+
+	t.Run("no meta", func(t *testing.T) {
+		// --- Given ---
+		err := New("em0", "ECode").Int("a", 1)
+
+		// --- When ---
+		data, jErr := json.Marshal(Public(err))
+
+		// --- Then ---
+		assert.NoError(t, jErr)
+		assert.Equal(t, `{"error":"internal error","code":"ECode"}`, string(data))
+	})
+
+	t.Run("constructed view", func(t *testing.T) {
+		// --- Given ---
+		view := PublicView{
+			Message: "public",
+			Meta:    map[string]any{"b": 2, "a": 1},
+		}
+
+		// --- When ---
+		data, jErr := json.Marshal(view)
+
+		// --- Then ---
+		assert.NoError(t, jErr)
+		want := `{"error":"public","code":"","meta":{"a":1,"b":2}}`
+		assert.Equal(t, want, string(data))
+	})
+}
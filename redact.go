@@ -0,0 +1,131 @@
+package zrr
+
+import (
+	"fmt"
+	"log/slog"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Redacted is the mask emitted instead of sensitive metadata values.
+const Redacted = "[REDACTED]"
+
+// secret represents sensitive metadata value. The value is masked when
+// serialized, formatted or logged.
+type secret struct{ val any }
+
+// String implements fmt.Stringer interface and returns the mask.
+func (s secret) String() string { return Redacted }
+
+// GoString implements fmt.GoStringer interface and returns the mask.
+func (s secret) GoString() string { return Redacted }
+
+// Format implements fmt.Formatter interface and prints the mask for all
+// verbs.
+func (s secret) Format(f fmt.State, _ rune) { _, _ = f.Write([]byte(Redacted)) }
+
+// MarshalJSON implements json.Marshaler interface and returns the mask.
+func (s secret) MarshalJSON() ([]byte, error) { return []byte(`"` + Redacted + `"`), nil }
+
+// LogValue implements slog.LogValuer interface and returns the mask.
+func (s secret) LogValue() slog.Value { return slog.StringValue(Redacted) }
+
+// Reveal returns the real value of metadata value v added with SecretStr or
+// Secret, other values are returned as is. Metadata accessors returning raw
+// values (GetMetadata, MetaAll, Fields and AllFields) return sensitive
+// values wrapped, so they stay masked when printed or serialized. Use
+// Reveal to get the real value, the Get* functions do it for you.
+func Reveal(v any) any {
+	if s, ok := v.(secret); ok {
+		return s.val
+	}
+	return v
+}
+
+// SecretStr adds the key with sensitive string val to the error. The value
+// is masked in MarshalJSON, fmt verbs and logs, GetStr returns the real
+// value.
+func (e *Error) SecretStr(key string, s string) *Error {
+	return e.with(key, secret{s})
+}
+
+// Secret adds the key with sensitive val to the error. The value is masked
+// in MarshalJSON, fmt verbs and logs, Get* functions return the real value.
+func (e *Error) Secret(key string, v any) *Error {
+	return e.with(key, secret{Reveal(v)})
+}
+
+// redactRules represents global redaction rules.
+type redactRules struct {
+	keys     map[string]struct{} // Lowercase key names.
+	patterns []*regexp.Regexp    // Key name patterns.
+}
+
+// redaction is the package wide redaction rules.
+var (
+	redactionMx sync.Mutex
+	redaction   atomic.Pointer[redactRules]
+)
+
+func init() { redaction.Store(&redactRules{keys: make(map[string]struct{})}) }
+
+// RedactKeys adds metadata key names which values are always masked. Key
+// names are matched case-insensitively.
+func RedactKeys(keys ...string) {
+	redactionMx.Lock()
+	defer redactionMx.Unlock()
+	cur := redaction.Load()
+	rules := &redactRules{keys: maps.Clone(cur.keys), patterns: cur.patterns}
+	for _, key := range keys {
+		rules.keys[strings.ToLower(key)] = struct{}{}
+	}
+	redaction.Store(rules)
+}
+
+// RedactPattern adds a regular expression matching metadata key names which
+// values are always masked.
+func RedactPattern(re *regexp.Regexp) {
+	redactionMx.Lock()
+	defer redactionMx.Unlock()
+	cur := redaction.Load()
+	rules := &redactRules{keys: cur.keys, patterns: slices.Clone(cur.patterns)}
+	rules.patterns = append(rules.patterns, re)
+	redaction.Store(rules)
+}
+
+// ResetRedaction removes all redaction rules added with RedactKeys and
+// RedactPattern. Values added with SecretStr and Secret are still masked.
+func ResetRedaction() {
+	redactionMx.Lock()
+	defer redactionMx.Unlock()
+	redaction.Store(&redactRules{keys: make(map[string]struct{})})
+}
+
+// IsRedacted returns true if the metadata key matches any of the redaction
+// rules.
+func IsRedacted(key string) bool {
+	rules := redaction.Load()
+	if _, ok := rules.keys[strings.ToLower(key)]; ok {
+		return true
+	}
+	for _, re := range rules.patterns {
+		if re.MatchString(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// Redact returns Redacted when v was added as a secret or the key matches
+// any of the redaction rules, otherwise it returns v. Custom serializers and
+// logger integrations should pass every metadata value through it.
+func Redact(key string, v any) any {
+	if _, ok := v.(secret); ok || IsRedacted(key) {
+		return Redacted
+	}
+	return v
+}
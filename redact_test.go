@@ -0,0 +1,238 @@
+package zrr
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+)
+
+// resetRedaction restores redaction rules when the test finishes.
+func resetRedaction(t *testing.T) {
+	t.Helper()
+	saved := redaction.Load()
+	t.Cleanup(func() { redaction.Store(saved) })
+}
+
+func Test_secret(t *testing.T) {
+	// --- Given ---
+	s := secret{"pass"}
+
+	// --- Then ---
+	assert.Equal(t, Redacted, s.String())
+	assert.Equal(t, Redacted, fmt.Sprintf("%v", s))
+	assert.Equal(t, Redacted, fmt.Sprintf("%+v", s))
+	assert.Equal(t, Redacted, fmt.Sprintf("%#v", s))
+	assert.Equal(t, Redacted, fmt.Sprintf("%s", s))
+	assert.Equal(t, Redacted, fmt.Sprintf("%d", s))
+	data, err := json.Marshal(s)
+	assert.NoError(t, err)
+	assert.Equal(t, `"[REDACTED]"`, string(data))
+	assert.Equal(t, Redacted, s.LogValue().String())
+}
+
+func Test_Error_SecretStr(t *testing.T) {
+	t.Run("mutable", func(t *testing.T) {
+		// --- Given ---
+		err := New("em0")
+
+		// --- When ---
+		have := err.SecretStr("password", "pass")
+
+		// --- Then ---
+		assert.Same(t, err, have)
+		val, ok := GetStr(have, "password")
+		assert.True(t, ok)
+		assert.Equal(t, "pass", val)
+		assert.True(t, HasKey(have, "password"))
+	})
+
+	t.Run("immutable", func(t *testing.T) {
+		// --- Given ---
+		err := Imm("em0")
+
+		// --- When ---
+		have := err.SecretStr("password", "pass")
+
+		// --- Then ---
+		assert.NotSame(t, err, have)
+		assert.False(t, HasKey(err, "password"))
+	})
+}
+
+func Test_Error_Secret(t *testing.T) {
+	// --- Given ---
+	err := New("em0")
+
+	// --- When ---
+	have := err.Secret("pin", 1234).Secret("ids", secret{[]string{"a"}})
+
+	// --- Then ---
+	val, ok := GetInt(have, "pin")
+	assert.True(t, ok)
+	assert.Equal(t, 1234, val)
+	ids, ok := GetStrs(have, "ids")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"a"}, ids)
+}
+
+func Test_Error_secretsMasked(t *testing.T) {
+	// --- Given ---
+	err := New("login {user} {password} failed", "ECode").
+		Str("user", "bob").
+		SecretStr("password", "pass")
+
+	t.Run("error message", func(t *testing.T) {
+		assert.Equal(t, "login bob [REDACTED] failed", err.Error())
+	})
+
+	t.Run("fmt", func(t *testing.T) {
+		// --- When ---
+		have := fmt.Sprintf("%+v", err)
+
+		// --- Then ---
+		want := "login bob [REDACTED] failed [ECode] user=bob password=[REDACTED]"
+		assert.Equal(t, want, have)
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		// --- When ---
+		data, jErr := json.Marshal(err)
+
+		// --- Then ---
+		assert.NoError(t, jErr)
+		want := `{
+			"error":"login bob [REDACTED] failed",
+			"code":"ECode",
+			"meta":{"user":"bob","password":"[REDACTED]"}
+		}`
+		assert.JSON(t, want, string(data))
+	})
+
+	t.Run("slog", func(t *testing.T) {
+		// --- Given ---
+		buf := &bytes.Buffer{}
+		log := slog.New(slog.NewJSONHandler(buf, nil))
+
+		// --- When ---
+		log.Info("msg", "err", err)
+
+		// --- Then ---
+		assert.Contain(t, `"password":"[REDACTED]"`, buf.String())
+		assert.NotContain(t, "pass\"", buf.String())
+	})
+
+	t.Run("wrapped", func(t *testing.T) {
+		// --- When ---
+		have := fmt.Errorf("w: %w", err)
+
+		// --- Then ---
+		val, _ := GetStr(have, "password")
+		assert.Equal(t, "pass", val)
+		assert.Equal(t, "w: login bob [REDACTED] failed", have.Error())
+	})
+}
+
+func Test_RedactKeys(t *testing.T) {
+	// --- Given ---
+	resetRedaction(t)
+
+	// --- When ---
+	RedactKeys("Password", "token")
+
+	// --- Then ---
+	assert.True(t, IsRedacted("password"))
+	assert.True(t, IsRedacted("PASSWORD"))
+	assert.True(t, IsRedacted("token"))
+	assert.False(t, IsRedacted("user"))
+
+	err := New("em0").Str("token", "abc").Str("user", "bob")
+	val, _ := GetStr(err, "token")
+	assert.Equal(t, "abc", val)
+	data, jErr := json.Marshal(err)
+	assert.NoError(t, jErr)
+	want := `{"error":"em0","code":"","meta":{"token":"[REDACTED]","user":"bob"}}`
+	assert.JSON(t, want, string(data))
+	assert.Equal(t, "em0 token=[REDACTED] user=bob", fmt.Sprintf("%+v", err))
+}
+
+func Test_RedactPattern(t *testing.T) {
+	// --- Given ---
+	resetRedaction(t)
+
+	// --- When ---
+	RedactPattern(regexp.MustCompile(`(?i)(secret|email)`))
+
+	// --- Then ---
+	assert.True(t, IsRedacted("client_secret"))
+	assert.True(t, IsRedacted("user_Email"))
+	assert.False(t, IsRedacted("user"))
+}
+
+func Test_ResetRedaction(t *testing.T) {
+	// --- Given ---
+	resetRedaction(t)
+	RedactKeys("token")
+	RedactPattern(regexp.MustCompile(`secret`))
+
+	// --- When ---
+	ResetRedaction()
+
+	// --- Then ---
+	assert.False(t, IsRedacted("token"))
+	assert.False(t, IsRedacted("secret"))
+	assert.Equal(t, Redacted, Redact("key", secret{"val"}))
+}
+
+func Test_Redact(t *testing.T) {
+	// --- Given ---
+	resetRedaction(t)
+	RedactKeys("token")
+
+	// --- Then ---
+	assert.Equal(t, Redacted, Redact("token", "abc"))
+	assert.Equal(t, Redacted, Redact("key", secret{"abc"}))
+	assert.Equal(t, "abc", Redact("key", "abc"))
+	assert.Equal(t, 1, Redact("key", 1))
+}
+
+func Test_Public_secret(t *testing.T) {
+	// --- Given ---
+	err := New("em0").SecretStr("email", "a@b.c").SetPublic("bad {email}", "email")
+
+	// --- When ---
+	view := Public(err)
+	data, jErr := json.Marshal(view)
+
+	// --- Then ---
+	assert.Equal(t, "bad [REDACTED]", view.Message)
+	assert.NoError(t, jErr)
+	want := `{"error":"bad [REDACTED]","code":"","meta":{"email":"[REDACTED]"}}`
+	assert.JSON(t, want, string(data))
+}
+
+func Test_Reveal(t *testing.T) {
+	// --- Given ---
+	err := New("em0").SecretStr("password", "pass").Str("user", "bob")
+
+	// --- Then ---
+	assert.Equal(t, "pass", Reveal(err.GetMetadata()["password"]))
+	assert.Equal(t, "pass", Reveal(err.MetaAll()["password"]))
+	assert.Equal(t, "bob", Reveal(err.GetMetadata()["user"]))
+	assert.Equal(t, Redacted, fmt.Sprint(err.GetMetadata()["password"]))
+	for key, val := range err.Fields() {
+		if key == "password" {
+			assert.Equal(t, "pass", Reveal(val))
+		}
+	}
+	for key, val := range AllFields(err) {
+		if key == "password" {
+			assert.Equal(t, "pass", Reveal(val))
+		}
+	}
+	assert.Nil(t, Reveal(nil))
+}
@@ -0,0 +1,81 @@
+package zrr
+
+import (
+	"slices"
+	"strings"
+	"sync"
+)
+
+// CodeInfo describes defaults associated with an error code.
+type CodeInfo struct {
+	// Error code.
+	Code string
+
+	// Retry classification of errors with the code.
+	Retry RetryClass
+
+	// Severity of errors with the code.
+	Severity Severity
+
+	// Kind of errors with the code.
+	Kind Kind
+
+	// Public message of errors with the code.
+	Public string
+
+	// Metadata keys of errors with the code which may be made public.
+	PublicKeys []string
+}
+
+// registry is the package wide error code registry.
+var (
+	registryMx sync.RWMutex
+	registry   = make(map[string]CodeInfo)
+)
+
+func init() {
+	Register(CodeInfo{Code: ECInvJSON, Kind: Invalid})
+	Register(CodeInfo{Code: ECCanceled, Kind: Canceled})
+	Register(CodeInfo{Code: ECDeadline, Kind: Timeout})
+	Register(CodeInfo{Code: ECPanic, Kind: Internal})
+}
+
+// Register registers error code defaults. Registering the same code again
+// replaces its defaults.
+func Register(info CodeInfo) {
+	registryMx.Lock()
+	defer registryMx.Unlock()
+	registry[info.Code] = info
+}
+
+// registerDefault registers error code defaults when the code is not
+// already registered.
+func registerDefault(info CodeInfo) {
+	registryMx.Lock()
+	defer registryMx.Unlock()
+	if _, ok := registry[info.Code]; !ok {
+		registry[info.Code] = info
+	}
+}
+
+// Lookup returns defaults registered for the error code.
+func Lookup(code string) (CodeInfo, bool) {
+	registryMx.RLock()
+	defer registryMx.RUnlock()
+	info, ok := registry[code]
+	return info, ok
+}
+
+// Registered returns all registered error code defaults sorted by code.
+func Registered() []CodeInfo {
+	registryMx.RLock()
+	defer registryMx.RUnlock()
+	infos := make([]CodeInfo, 0, len(registry))
+	for _, info := range registry {
+		infos = append(infos, info)
+	}
+	slices.SortFunc(infos, func(a, b CodeInfo) int {
+		return strings.Compare(a.Code, b.Code)
+	})
+	return infos
+}
@@ -0,0 +1,74 @@
+package zrr
+
+import (
+	"maps"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+)
+
+// resetRegistry restores the error code registry when the test finishes.
+func resetRegistry(t *testing.T) {
+	t.Helper()
+	registryMx.Lock()
+	saved := maps.Clone(registry)
+	registryMx.Unlock()
+	t.Cleanup(func() {
+		registryMx.Lock()
+		registry = saved
+		registryMx.Unlock()
+	})
+}
+
+func Test_Register(t *testing.T) {
+	t.Run("register", func(t *testing.T) {
+		// --- Given ---
+		resetRegistry(t)
+
+		// --- When ---
+		Register(CodeInfo{Code: "ECTest", Retry: Retryable})
+
+		// --- Then ---
+		got, ok := Lookup("ECTest")
+		assert.True(t, ok)
+		assert.Equal(t, CodeInfo{Code: "ECTest", Retry: Retryable}, got)
+	})
+
+	t.Run("replace", func(t *testing.T) {
+		// --- Given ---
+		resetRegistry(t)
+		Register(CodeInfo{Code: "ECTest", Retry: Retryable})
+
+		// --- When ---
+		Register(CodeInfo{Code: "ECTest", Retry: Permanent})
+
+		// --- Then ---
+		got, _ := Lookup("ECTest")
+		assert.Equal(t, Permanent, got.Retry)
+	})
+}
+
+func Test_Lookup_notRegistered(t *testing.T) {
+	// --- When ---
+	got, ok := Lookup("ECNotRegistered")
+
+	// --- Then ---
+	assert.False(t, ok)
+	assert.Zero(t, got)
+}
+
+func Test_Registered(t *testing.T) {
+	// --- Given ---
+	resetRegistry(t)
+	registryMx.Lock()
+	registry = make(map[string]CodeInfo)
+	registryMx.Unlock()
+	Register(CodeInfo{Code: "ECb"})
+	Register(CodeInfo{Code: "ECa"})
+
+	// --- When ---
+	got := Registered()
+
+	// --- Then ---
+	assert.Equal(t, []CodeInfo{{Code: "ECa"}, {Code: "ECb"}}, got)
+}
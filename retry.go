@@ -0,0 +1,195 @@
+package zrr
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// RetryClass represents error retry classification.
+type RetryClass int
+
+const (
+	// Unclassified represents error which is neither retryable nor permanent.
+	Unclassified RetryClass = iota
+
+	// Retryable represents error which may succeed when retried.
+	Retryable
+
+	// Permanent represents error which will not succeed when retried.
+	Permanent
+)
+
+// Retry metadata keys.
+const (
+	// KeyRetryable is the metadata key for the per error retry
+	// classification. The value is true for retryable and false for
+	// permanent errors.
+	KeyRetryable = "retryable"
+
+	// KeyRetryAfter is the metadata key for the retry delay hint.
+	KeyRetryAfter = "retry_after"
+
+	// KeyAttempts is the metadata key for the number of attempts made by
+	// the Retry function.
+	KeyAttempts = "attempts"
+
+	// KeyAttemptCodes is the metadata key for the list of error codes
+	// returned by each attempt made by the Retry function.
+	KeyAttemptCodes = "attempt_codes"
+)
+
+// SetRetryable marks the error as retryable (true) or permanent (false)
+// overriding defaults registered for its code.
+func (e *Error) SetRetryable(retryable bool) *Error {
+	return e.with(KeyRetryable, retryable)
+}
+
+// RetryAfter sets the hint how long to wait before retrying.
+func (e *Error) RetryAfter(d time.Duration) *Error {
+	return e.with(KeyRetryAfter, d)
+}
+
+// GetRetryClass returns retry classification of err. The err chain is
+// walked and the first Error instance marked with SetRetryable or with a
+// code registered with retry classification decides.
+func GetRetryClass(err error) RetryClass {
+	for e := range Chain(err) {
+		if retryable, ok := e.meta[KeyRetryable].(bool); ok {
+			if retryable {
+				return Retryable
+			}
+			return Permanent
+		}
+		if info, ok := Lookup(e.code); ok && info.Retry != Unclassified {
+			return info.Retry
+		}
+	}
+	return Unclassified
+}
+
+// IsRetryable returns true if err is classified as retryable.
+func IsRetryable(err error) bool { return GetRetryClass(err) == Retryable }
+
+// IsPermanent returns true if err is classified as permanent.
+func IsPermanent(err error) bool { return GetRetryClass(err) == Permanent }
+
+// GetRetryAfter returns the retry delay hint set with RetryAfter on the
+// first Error instance in the err chain which has it.
+func GetRetryAfter(err error) (time.Duration, bool) {
+	for e := range Chain(err) {
+		if d, ok := e.meta[KeyRetryAfter].(time.Duration); ok {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+// RetryPolicy represents retry policy used by the Retry function.
+type RetryPolicy struct {
+	// Maximum number of attempts. Default 3.
+	MaxAttempts int
+
+	// Delay before the second attempt. Default 100ms.
+	BaseDelay time.Duration
+
+	// Maximum delay between attempts. Zero means no limit.
+	MaxDelay time.Duration
+
+	// Delay multiplier applied after each attempt. Default 2.
+	Multiplier float64
+
+	// Jitter as a fraction of the delay in range [0, 1]. The delay is
+	// randomly reduced by up to Jitter * delay.
+	Jitter float64
+
+	// Retry errors which are neither retryable nor permanent.
+	RetryUnclassified bool
+}
+
+// delay returns the delay before the next attempt.
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	base := p.BaseDelay
+	if base <= 0 {
+		base = 100 * time.Millisecond
+	}
+	mul := p.Multiplier
+	if mul <= 0 {
+		mul = 2
+	}
+	d := float64(base) * math.Pow(mul, float64(attempt-1))
+	if p.MaxDelay > 0 && d > float64(p.MaxDelay) {
+		d = float64(p.MaxDelay)
+	}
+	if p.Jitter > 0 {
+		d -= d * min(p.Jitter, 1) * rand.Float64()
+	}
+	return time.Duration(d)
+}
+
+// Retry calls fn until it succeeds, returns error which should not be
+// retried, the maximum number of attempts is reached or ctx is done. The
+// delay between attempts grows exponentially according to the policy,
+// unless the error carries the RetryAfter hint which is longer.
+//
+// Errors classified as permanent are never retried, errors which are not
+// classified are retried only when RetryPolicy.RetryUnclassified is set.
+//
+// The returned error wraps the last error returned by fn and has metadata
+// with the number of attempts (KeyAttempts), the total elapsed time
+// (KeyElapsed) and the list of error codes returned by each attempt
+// (KeyAttemptCodes). When ctx is done while waiting, the context cause is
+// joined with the last error.
+func Retry(ctx context.Context, policy RetryPolicy, fn func(context.Context) error) error {
+	maxAttempts := policy.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 3
+	}
+
+	start := time.Now()
+	var codes []string
+	var err error
+	var attempt int
+	for attempt = 1; ; attempt++ {
+		if err = fn(ctx); isNil(err) {
+			return nil
+		}
+		codes = append(codes, GetCode(err))
+
+		class := GetRetryClass(err)
+		if attempt >= maxAttempts || class == Permanent ||
+			(class == Unclassified && !policy.RetryUnclassified) {
+			break
+		}
+
+		d := policy.delay(attempt)
+		if hint, ok := GetRetryAfter(err); ok && hint > d {
+			d = hint
+		}
+
+		if !sleep(ctx, d) {
+			err = errors.Join(err, context.Cause(ctx))
+			break
+		}
+	}
+
+	return Wrap(err).
+		Int(KeyAttempts, attempt).
+		Duration(KeyElapsed, time.Since(start)).
+		with(KeyAttemptCodes, codes)
+}
+
+// sleep waits for duration d or until ctx is done. It returns false if ctx
+// is done before d elapses.
+func sleep(ctx context.Context, d time.Duration) bool {
+	tim := time.NewTimer(d)
+	defer tim.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-tim.C:
+		return true
+	}
+}
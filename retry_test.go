@@ -0,0 +1,288 @@
+package zrr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ctx42/testing/pkg/assert"
+)
+
+func Test_Error_SetRetryable(t *testing.T) {
+	// --- When ---
+	err0 := New("em0").SetRetryable(true)
+	err1 := New("em0").SetRetryable(false)
+
+	// --- Then ---
+	assert.Equal(t, map[string]any{KeyRetryable: true}, err0.GetMetadata())
+	assert.Equal(t, map[string]any{KeyRetryable: false}, err1.GetMetadata())
+}
+
+func Test_Error_RetryAfter(t *testing.T) {
+	// --- When ---
+	err := New("em0").RetryAfter(time.Second)
+
+	// --- Then ---
+	assert.Equal(t, map[string]any{KeyRetryAfter: time.Second}, err.GetMetadata())
+}
+
+func Test_GetRetryClass(t *testing.T) {
+	resetRegistry(t)
+	Register(CodeInfo{Code: "ECRetry", Retry: Retryable})
+	Register(CodeInfo{Code: "ECPerm", Retry: Permanent})
+	Register(CodeInfo{Code: "ECNone"})
+
+	tt := []struct {
+		testN string
+
+		exp RetryClass
+		err error
+	}{
+		{"registered retryable", Retryable, New("em0", "ECRetry")},
+		{"registered permanent", Permanent, New("em0", "ECPerm")},
+		{"registered unclassified", Unclassified, New("em0", "ECNone")},
+		{"not registered", Unclassified, New("em0", "ECOther")},
+		{"marked retryable", Retryable, New("em0", "ECPerm").SetRetryable(true)},
+		{"marked permanent", Permanent, New("em0", "ECRetry").SetRetryable(false)},
+		{"immutable clone", Retryable, Imm("em0", "ECRetry").Str("k", "v")},
+		{"in chain", Permanent, fmt.Errorf("w: %w", New("em0", "ECPerm"))},
+		{"closest wins", Retryable, Wrap(fmt.Errorf("w: %w", New("em0", "ECPerm")), "ECRetry")},
+		{"skip unclassified", Permanent, Wrap(fmt.Errorf("w: %w", New("em0", "ECPerm")), "ECNone")},
+		{"std error", Unclassified, errors.New("em0")},
+		{"nil", Unclassified, nil},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			assert.Equal(t, tc.exp, GetRetryClass(tc.err))
+		})
+	}
+}
+
+func Test_IsRetryable(t *testing.T) {
+	assert.True(t, IsRetryable(New("em0").SetRetryable(true)))
+	assert.False(t, IsRetryable(New("em0").SetRetryable(false)))
+	assert.False(t, IsRetryable(New("em0")))
+}
+
+func Test_IsPermanent(t *testing.T) {
+	assert.True(t, IsPermanent(New("em0").SetRetryable(false)))
+	assert.False(t, IsPermanent(New("em0").SetRetryable(true)))
+	assert.False(t, IsPermanent(New("em0")))
+}
+
+func Test_GetRetryAfter(t *testing.T) {
+	t.Run("set", func(t *testing.T) {
+		// --- Given ---
+		err := fmt.Errorf("w: %w", New("em0").RetryAfter(time.Second))
+
+		// --- When ---
+		got, ok := GetRetryAfter(err)
+
+		// --- Then ---
+		assert.True(t, ok)
+		assert.Equal(t, time.Second, got)
+	})
+
+	t.Run("not set", func(t *testing.T) {
+		// --- When ---
+		got, ok := GetRetryAfter(New("em0"))
+
+		// --- Then ---
+		assert.False(t, ok)
+		assert.Equal(t, time.Duration(0), got)
+	})
+}
+
+func Test_RetryPolicy_delay(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		// --- Given ---
+		p := RetryPolicy{}
+
+		// --- Then ---
+		assert.Equal(t, 100*time.Millisecond, p.delay(1))
+		assert.Equal(t, 200*time.Millisecond, p.delay(2))
+		assert.Equal(t, 400*time.Millisecond, p.delay(3))
+	})
+
+	t.Run("max delay", func(t *testing.T) {
+		// --- Given ---
+		p := RetryPolicy{BaseDelay: time.Second, MaxDelay: 3 * time.Second, Multiplier: 3}
+
+		// --- Then ---
+		assert.Equal(t, time.Second, p.delay(1))
+		assert.Equal(t, 3*time.Second, p.delay(2))
+		assert.Equal(t, 3*time.Second, p.delay(3))
+	})
+
+	t.Run("jitter", func(t *testing.T) {
+		// --- Given ---
+		p := RetryPolicy{BaseDelay: time.Second, Jitter: 0.5}
+
+		// --- When ---
+		for range 100 {
+			got := p.delay(1)
+
+			// --- Then ---
+			assert.True(t, got > 500*time.Millisecond)
+			assert.True(t, got <= time.Second)
+		}
+	})
+}
+
+func Test_Retry(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}
+
+	t.Run("success", func(t *testing.T) {
+		// --- Given ---
+		var calls int
+		fn := func(context.Context) error {
+			calls++
+			if calls < 2 {
+				return New("em0", "ECTmp").SetRetryable(true)
+			}
+			return nil
+		}
+
+		// --- When ---
+		err := Retry(context.Background(), policy, fn)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("max attempts", func(t *testing.T) {
+		// --- Given ---
+		var calls int
+		fn := func(context.Context) error {
+			calls++
+			return New("em0", fmt.Sprintf("EC%d", calls)).SetRetryable(true)
+		}
+
+		// --- When ---
+		err := Retry(context.Background(), policy, fn)
+
+		// --- Then ---
+		assert.Equal(t, 3, calls)
+		assert.Equal(t, "EC3", GetCode(err))
+		attempts, _ := GetInt(err, KeyAttempts)
+		assert.Equal(t, 3, attempts)
+		elapsed, _ := GetDuration(err, KeyElapsed)
+		assert.True(t, elapsed >= 3*time.Millisecond)
+		codes, _ := GetStrs(err, KeyAttemptCodes)
+		assert.Equal(t, []string{"EC1", "EC2", "EC3"}, codes)
+	})
+
+	t.Run("permanent", func(t *testing.T) {
+		// --- Given ---
+		var calls int
+		e := errors.New("std error")
+		fn := func(context.Context) error {
+			calls++
+			return Wrap(e, "ECPerm").SetRetryable(false)
+		}
+
+		// --- When ---
+		err := Retry(context.Background(), policy, fn)
+
+		// --- Then ---
+		assert.Equal(t, 1, calls)
+		assert.ErrorIs(t, e, err)
+		attempts, _ := GetInt(err, KeyAttempts)
+		assert.Equal(t, 1, attempts)
+	})
+
+	t.Run("registered retryable", func(t *testing.T) {
+		// --- Given ---
+		resetRegistry(t)
+		Register(CodeInfo{Code: "ECBusy", Retry: Retryable})
+		var calls int
+		fn := func(context.Context) error {
+			calls++
+			return New("em0", "ECBusy")
+		}
+
+		// --- When ---
+		err := Retry(context.Background(), policy, fn)
+
+		// --- Then ---
+		assert.Equal(t, 3, calls)
+		assert.True(t, HasCode(err, "ECBusy"))
+	})
+
+	t.Run("unclassified", func(t *testing.T) {
+		// --- Given ---
+		var calls int
+		fn := func(context.Context) error {
+			calls++
+			return errors.New("std error")
+		}
+
+		// --- When ---
+		err := Retry(context.Background(), policy, fn)
+
+		// --- Then ---
+		assert.Equal(t, 1, calls)
+		codes, _ := GetStrs(err, KeyAttemptCodes)
+		assert.Equal(t, []string{""}, codes)
+	})
+
+	t.Run("retry unclassified", func(t *testing.T) {
+		// --- Given ---
+		p := policy
+		p.RetryUnclassified = true
+		var calls int
+		fn := func(context.Context) error {
+			calls++
+			return errors.New("std error")
+		}
+
+		// --- When ---
+		_ = Retry(context.Background(), p, fn)
+
+		// --- Then ---
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("retry after hint", func(t *testing.T) {
+		// --- Given ---
+		var calls int
+		fn := func(context.Context) error {
+			calls++
+			return New("em0").SetRetryable(true).RetryAfter(20 * time.Millisecond)
+		}
+		p := policy
+		p.MaxAttempts = 2
+
+		// --- When ---
+		err := Retry(context.Background(), p, fn)
+
+		// --- Then ---
+		assert.Equal(t, 2, calls)
+		elapsed, _ := GetDuration(err, KeyElapsed)
+		assert.True(t, elapsed >= 20*time.Millisecond)
+	})
+
+	t.Run("context done", func(t *testing.T) {
+		// --- Given ---
+		ctx, cancel := context.WithCancel(context.Background())
+		var calls int
+		fn := func(context.Context) error {
+			calls++
+			cancel()
+			return New("em0").SetRetryable(true)
+		}
+
+		// --- When ---
+		err := Retry(ctx, RetryPolicy{BaseDelay: time.Hour}, fn)
+
+		// --- Then ---
+		assert.Equal(t, 1, calls)
+		assert.ErrorIs(t, context.Canceled, err)
+		attempts, _ := GetInt(err, KeyAttempts)
+		assert.Equal(t, 1, attempts)
+	})
+}
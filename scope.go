@@ -0,0 +1,150 @@
+package zrr
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Metadata keys set by scoped error factories.
+const (
+	// KeyComponent is the metadata key for the scope (component) name.
+	KeyComponent = "component"
+
+	// KeyVersion is the metadata key for the component version.
+	KeyVersion = "version"
+)
+
+// ScopeOption represents an option used when creating Factory.
+type ScopeOption func(*Factory)
+
+// ScopeMeta is a scope option adding the key with val to the default
+// metadata of errors created by the factory.
+func ScopeMeta(key string, val any) ScopeOption {
+	return func(f *Factory) { f.set(key, val) }
+}
+
+// ScopeVersion is a scope option adding the component version to the
+// default metadata of errors created by the factory.
+func ScopeVersion(ver string) ScopeOption { return ScopeMeta(KeyVersion, ver) }
+
+// ScopeDefaults is a scope option setting defaults used when registering
+// codes of errors created by the factory. The Code field is ignored.
+func ScopeDefaults(info CodeInfo) ScopeOption {
+	return func(f *Factory) { f.info = info }
+}
+
+// Factory creates errors for a scope (component). Codes of errors created
+// by the factory are prefixed with the scope name and registered in the code
+// registry, the errors have default metadata attached.
+type Factory struct {
+	name string         // Scope name.
+	info CodeInfo       // Defaults for registered codes.
+	keys []string       // Default metadata keys in the insertion order.
+	meta map[string]any // Default metadata.
+}
+
+// Scope returns error factory for the scope with given name. The name is
+// used as the code prefix and added to the default metadata under
+// KeyComponent key.
+func Scope(name string, opts ...ScopeOption) *Factory {
+	f := &Factory{name: name, meta: make(map[string]any)}
+	f.set(KeyComponent, name)
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
+}
+
+// set sets the default metadata key to value v.
+func (f *Factory) set(key string, v any) {
+	if _, ok := f.meta[key]; !ok {
+		f.keys = append(f.keys, key)
+	}
+	f.meta[key] = v
+}
+
+// Name returns the scope name.
+func (f *Factory) Name() string { return f.name }
+
+// Code returns code prefixed with the scope name and CodeSep. Codes which
+// are empty or already prefixed are returned as is.
+func (f *Factory) Code(code string) string {
+	if code == "" || strings.HasPrefix(code, f.name+CodeSep) {
+		return code
+	}
+	return f.name + CodeSep + code
+}
+
+// Register registers error code defaults for the code prefixed with the
+// scope name.
+func (f *Factory) Register(info CodeInfo) {
+	info.Code = f.Code(info.Code)
+	Register(info)
+}
+
+// New is a constructor returning new Error instance with the scope's default
+// metadata. Error code is optional, if more than one code is provided the
+// first one will be used.
+func (f *Factory) New(msg string, code ...string) *Error {
+	return f.decorate(tpl(base(errors.New(msg), false, f.codes(code)...)))
+}
+
+// Newf is a constructor returning new Error instance with the scope's
+// default metadata. Arguments are handled in the same manner as in
+// fmt.Errorf.
+func (f *Factory) Newf(msg string, args ...interface{}) *Error {
+	return f.decorate(base(fmt.Errorf(msg, args...), false))
+}
+
+// Imm is a constructor returning new immutable Error instance with the
+// scope's default metadata. Error code is optional, if more than one code is
+// provided the first one will be used.
+func (f *Factory) Imm(msg string, code ...string) *Error {
+	return f.decorate(tpl(base(errors.New(msg), true, f.codes(code)...)))
+}
+
+// Wrap wraps err in Error instance the same way the Wrap function does and
+// adds the scope's default metadata. Metadata keys already set on err are
+// not overwritten. It returns nil if err is nil or typed nil (nil pointer).
+func (f *Factory) Wrap(err error, code ...string) *Error {
+	e := Wrap(err, f.codes(code)...)
+	if e == nil {
+		return nil
+	}
+	keys := make([]string, 0, len(f.keys))
+	for _, key := range f.keys {
+		if _, ok := e.meta[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return e
+	}
+	ne := e.mutable()
+	for _, key := range keys {
+		ne.set(key, copyValue(f.meta[key]))
+	}
+	return ne
+}
+
+// codes returns the first code prefixed with the scope name and registers
+// it in the code registry.
+func (f *Factory) codes(code []string) []string {
+	if len(code) == 0 || code[0] == "" {
+		return nil
+	}
+	info := f.info
+	info.Code = f.Code(code[0])
+	registerDefault(info)
+	return []string{info.Code}
+}
+
+// decorate adds the scope's default metadata to e. It must be called only
+// on newly created instances.
+func (f *Factory) decorate(e *Error) *Error {
+	for _, key := range f.keys {
+		e.set(key, copyValue(f.meta[key]))
+	}
+	return e
+}
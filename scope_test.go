@@ -0,0 +1,246 @@
+package zrr
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+)
+
+func Test_Scope(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		// --- When ---
+		f := Scope("billing")
+
+		// --- Then ---
+		assert.Equal(t, "billing", f.Name())
+		assert.Equal(t, []string{KeyComponent}, f.keys)
+		assert.Equal(t, map[string]any{KeyComponent: "billing"}, f.meta)
+	})
+
+	t.Run("options", func(t *testing.T) {
+		// --- When ---
+		f := Scope(
+			"billing",
+			ScopeVersion("v1.2.3"),
+			ScopeMeta("region", "eu"),
+			ScopeDefaults(CodeInfo{Kind: Internal}),
+		)
+
+		// --- Then ---
+		assert.Equal(t, []string{KeyComponent, KeyVersion, "region"}, f.keys)
+		want := map[string]any{
+			KeyComponent: "billing",
+			KeyVersion:   "v1.2.3",
+			"region":     "eu",
+		}
+		assert.Equal(t, want, f.meta)
+		assert.Equal(t, CodeInfo{Kind: Internal}, f.info)
+	})
+}
+
+func Test_Factory_Code(t *testing.T) {
+	// --- Given ---
+	f := Scope("billing")
+
+	// --- Then ---
+	assert.Equal(t, "billing.charge", f.Code("charge"))
+	assert.Equal(t, "billing.charge", f.Code("billing.charge"))
+	assert.Equal(t, "billing.billing", f.Code("billing"))
+	assert.Equal(t, "", f.Code(""))
+}
+
+func Test_Factory_Register(t *testing.T) {
+	// --- Given ---
+	resetRegistry(t)
+	f := Scope("billing")
+
+	// --- When ---
+	f.Register(CodeInfo{Code: "charge", Kind: Conflict})
+
+	// --- Then ---
+	info, ok := Lookup("billing.charge")
+	assert.True(t, ok)
+	assert.Equal(t, CodeInfo{Code: "billing.charge", Kind: Conflict}, info)
+}
+
+func Test_Factory_New(t *testing.T) {
+	t.Run("with code", func(t *testing.T) {
+		// --- Given ---
+		resetRegistry(t)
+		f := Scope("billing", ScopeDefaults(CodeInfo{Kind: Internal}))
+
+		// --- When ---
+		err := f.New("em0", "charge")
+
+		// --- Then ---
+		assert.Equal(t, "em0", err.Error())
+		assert.Equal(t, "billing.charge", err.ErrCode())
+		assert.False(t, err.imm)
+		assert.Equal(t, map[string]any{KeyComponent: "billing"}, err.meta)
+
+		info, ok := Lookup("billing.charge")
+		assert.True(t, ok)
+		assert.Equal(t, CodeInfo{Code: "billing.charge", Kind: Internal}, info)
+		assert.Equal(t, Internal, GetKind(err))
+	})
+
+	t.Run("without code", func(t *testing.T) {
+		// --- Given ---
+		resetRegistry(t)
+		f := Scope("billing")
+
+		// --- When ---
+		err := f.New("em0")
+
+		// --- Then ---
+		assert.Equal(t, "", err.ErrCode())
+		_, ok := Lookup("")
+		assert.False(t, ok)
+	})
+
+	t.Run("registered code is not replaced", func(t *testing.T) {
+		// --- Given ---
+		resetRegistry(t)
+		f := Scope("billing", ScopeDefaults(CodeInfo{Kind: Internal}))
+		f.Register(CodeInfo{Code: "charge", Kind: Conflict})
+
+		// --- When ---
+		err := f.New("em0", "charge")
+
+		// --- Then ---
+		assert.Equal(t, Conflict, GetKind(err))
+	})
+
+	t.Run("default metadata is copied", func(t *testing.T) {
+		// --- Given ---
+		resetRegistry(t)
+		f := Scope("billing", ScopeMeta("tags", []string{"a"}))
+
+		// --- When ---
+		err := f.New("em0").AppendStr("tags", "b")
+
+		// --- Then ---
+		have, _ := GetStrs(err, "tags")
+		assert.Equal(t, []string{"a", "b"}, have)
+		assert.Equal(t, []string{"a"}, f.meta["tags"])
+	})
+}
+
+func Test_Factory_Newf(t *testing.T) {
+	// --- Given ---
+	resetRegistry(t)
+	f := Scope("billing", ScopeVersion("v1"))
+
+	// --- When ---
+	err := f.Newf("em%d", 0)
+
+	// --- Then ---
+	assert.Equal(t, "em0", err.Error())
+	assert.Equal(t, "", err.ErrCode())
+	assert.Equal(t, []string{KeyComponent, KeyVersion}, err.keys)
+}
+
+func Test_Factory_Imm(t *testing.T) {
+	// --- Given ---
+	resetRegistry(t)
+	f := Scope("billing")
+
+	// --- When ---
+	err := f.Imm("em0", "charge")
+
+	// --- Then ---
+	assert.True(t, err.imm)
+	assert.Equal(t, "billing.charge", err.ErrCode())
+	have, _ := GetStr(err, KeyComponent)
+	assert.Equal(t, "billing", have)
+	_, ok := Lookup("billing.charge")
+	assert.True(t, ok)
+
+	e1 := err.Str("key", "val")
+	assert.NotSame(t, err, e1)
+	assert.Equal(t, []string{KeyComponent}, err.keys)
+}
+
+func Test_Factory_Wrap(t *testing.T) {
+	t.Run("std error", func(t *testing.T) {
+		// --- Given ---
+		resetRegistry(t)
+		f := Scope("billing")
+		e0 := errors.New("em0")
+
+		// --- When ---
+		err := f.Wrap(e0, "charge")
+
+		// --- Then ---
+		assert.Same(t, e0, err.Unwrap())
+		assert.Equal(t, "billing.charge", err.ErrCode())
+		assert.Equal(t, map[string]any{KeyComponent: "billing"}, err.meta)
+		_, ok := Lookup("billing.charge")
+		assert.True(t, ok)
+	})
+
+	t.Run("existing keys are kept", func(t *testing.T) {
+		// --- Given ---
+		resetRegistry(t)
+		e0 := Scope("db").New("em0", "conn")
+		f := Scope("billing", ScopeVersion("v1"))
+
+		// --- When ---
+		err := f.Wrap(e0, "charge")
+
+		// --- Then ---
+		assert.Same(t, e0, err)
+		assert.Equal(t, "billing.charge", err.ErrCode())
+		assert.Equal(t, []string{"db.conn", "billing.charge"}, err.CodeHistory())
+		want := map[string]any{KeyComponent: "db", KeyVersion: "v1"}
+		assert.Equal(t, want, err.meta)
+	})
+
+	t.Run("immutable", func(t *testing.T) {
+		// --- Given ---
+		resetRegistry(t)
+		e0 := Imm("em0")
+		f := Scope("billing")
+
+		// --- When ---
+		err := f.Wrap(e0)
+
+		// --- Then ---
+		assert.NotSame(t, e0, err)
+		assert.Equal(t, map[string]any{KeyComponent: "billing"}, err.meta)
+		assert.Equal(t, map[string]any{}, e0.meta)
+	})
+
+	t.Run("nothing to add", func(t *testing.T) {
+		// --- Given ---
+		resetRegistry(t)
+		e0 := Scope("billing").Imm("em0")
+		f := Scope("billing")
+
+		// --- When ---
+		err := f.Wrap(e0)
+
+		// --- Then ---
+		assert.Same(t, e0, err)
+	})
+
+	t.Run("wrapped", func(t *testing.T) {
+		// --- Given ---
+		resetRegistry(t)
+		f := Scope("billing")
+
+		// --- When ---
+		err := f.Wrap(fmt.Errorf("w: %w", New("em0")))
+
+		// --- Then ---
+		assert.Equal(t, "w: em0", err.Error())
+		have, _ := GetStr(err, KeyComponent)
+		assert.Equal(t, "billing", have)
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		assert.Nil(t, Scope("billing").Wrap(nil))
+	})
+}
@@ -0,0 +1,84 @@
+package zrr
+
+import (
+	"log/slog"
+)
+
+// Severity represents error severity level.
+type Severity int
+
+// Error severity levels.
+const (
+	// SeverityDefault represents severity which was not set. It should be
+	// treated as SeverityError.
+	SeverityDefault Severity = iota
+	SeverityDebug
+	SeverityInfo
+	SeverityWarning
+	SeverityError
+	SeverityCritical
+)
+
+// severityNames maps severity levels to their names.
+var severityNames = map[Severity]string{
+	SeverityDebug:    "debug",
+	SeverityInfo:     "info",
+	SeverityWarning:  "warning",
+	SeverityError:    "error",
+	SeverityCritical: "critical",
+}
+
+// String returns severity name. It returns empty string for SeverityDefault.
+func (s Severity) String() string { return severityNames[s] }
+
+// Level returns slog level corresponding to the severity. SeverityCritical
+// is mapped to a level above slog.LevelError.
+func (s Severity) Level() slog.Level {
+	switch s {
+	case SeverityDebug:
+		return slog.LevelDebug
+	case SeverityInfo:
+		return slog.LevelInfo
+	case SeverityWarning:
+		return slog.LevelWarn
+	case SeverityCritical:
+		return slog.LevelError + 4
+	default:
+		return slog.LevelError
+	}
+}
+
+// ParseSeverity returns severity for its name. It returns SeverityDefault
+// for unknown names.
+func ParseSeverity(name string) Severity {
+	for sev, n := range severityNames {
+		if n == name {
+			return sev
+		}
+	}
+	return SeverityDefault
+}
+
+// SetSeverity sets error severity. When the error is immutable the severity
+// is set on its mutable copy.
+func (e *Error) SetSeverity(s Severity) *Error {
+	ne := e.mutable()
+	ne.sev = s
+	return ne
+}
+
+// GetSeverity returns severity of err. The err chain is walked and the
+// first Error instance with severity set with SetSeverity or with a code
+// registered with severity decides. It returns SeverityDefault when the
+// severity is not set.
+func GetSeverity(err error) Severity {
+	for e := range Chain(err) {
+		if e.sev != SeverityDefault {
+			return e.sev
+		}
+		if info, ok := Lookup(e.code); ok && info.Severity != SeverityDefault {
+			return info.Severity
+		}
+	}
+	return SeverityDefault
+}
@@ -0,0 +1,179 @@
+package zrr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+)
+
+func Test_Severity_String(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		sev Severity
+		exp string
+	}{
+		{"default", SeverityDefault, ""},
+		{"debug", SeverityDebug, "debug"},
+		{"info", SeverityInfo, "info"},
+		{"warning", SeverityWarning, "warning"},
+		{"error", SeverityError, "error"},
+		{"critical", SeverityCritical, "critical"},
+		{"unknown", Severity(100), ""},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			assert.Equal(t, tc.exp, tc.sev.String())
+			if tc.exp != "" {
+				assert.Equal(t, tc.sev, ParseSeverity(tc.exp))
+			}
+		})
+	}
+}
+
+func Test_ParseSeverity_unknown(t *testing.T) {
+	assert.Equal(t, SeverityDefault, ParseSeverity(""))
+	assert.Equal(t, SeverityDefault, ParseSeverity("fatal"))
+}
+
+func Test_Severity_Level(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		sev Severity
+		exp slog.Level
+	}{
+		{"default", SeverityDefault, slog.LevelError},
+		{"debug", SeverityDebug, slog.LevelDebug},
+		{"info", SeverityInfo, slog.LevelInfo},
+		{"warning", SeverityWarning, slog.LevelWarn},
+		{"error", SeverityError, slog.LevelError},
+		{"critical", SeverityCritical, slog.LevelError + 4},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			assert.Equal(t, tc.exp, tc.sev.Level())
+		})
+	}
+}
+
+func Test_Error_SetSeverity(t *testing.T) {
+	t.Run("mutable", func(t *testing.T) {
+		// --- Given ---
+		err := New("em0")
+
+		// --- When ---
+		got := err.SetSeverity(SeverityWarning)
+
+		// --- Then ---
+		assert.Same(t, err, got)
+		assert.Equal(t, SeverityWarning, GetSeverity(got))
+	})
+
+	t.Run("immutable", func(t *testing.T) {
+		// --- Given ---
+		err := Imm("em0")
+
+		// --- When ---
+		got := err.SetSeverity(SeverityWarning)
+
+		// --- Then ---
+		assert.NotSame(t, err, got)
+		assert.Equal(t, SeverityWarning, GetSeverity(got))
+		assert.Equal(t, SeverityDefault, GetSeverity(err))
+	})
+}
+
+func Test_GetSeverity(t *testing.T) {
+	resetRegistry(t)
+	Register(CodeInfo{Code: "ECCorrupt", Severity: SeverityCritical})
+	Register(CodeInfo{Code: "ECNone"})
+
+	imm := Imm("em0", "ECode")
+	imm.sev = SeverityInfo
+
+	tt := []struct {
+		testN string
+
+		exp Severity
+		err error
+	}{
+		{"not set", SeverityDefault, New("em0")},
+		{"set", SeverityDebug, New("em0").SetSeverity(SeverityDebug)},
+		{"registered", SeverityCritical, New("em0", "ECCorrupt")},
+		{"set overrides registered", SeverityInfo, New("em0", "ECCorrupt").SetSeverity(SeverityInfo)},
+		{"registered without severity", SeverityDefault, New("em0", "ECNone")},
+		{"wrapped", SeverityCritical, Wrap(fmt.Errorf("w: %w", New("em0", "ECCorrupt")))},
+		{"wrapped with new code", SeverityCritical, Wrap(New("em0").SetSeverity(SeverityCritical), "ECNone")},
+		{"immutable clone", SeverityInfo, imm.Str("k", "v")},
+		{"immutable re-coded", SeverityInfo, Wrap(imm, "ECNone")},
+		{"std error", SeverityDefault, errors.New("em0")},
+		{"nil", SeverityDefault, nil},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			assert.Equal(t, tc.exp, GetSeverity(tc.err))
+		})
+	}
+}
+
+func Test_Error_Clone_severity(t *testing.T) {
+	// --- Given ---
+	err := New("em0").SetSeverity(SeverityWarning)
+
+	// --- When ---
+	got := err.Clone()
+
+	// --- Then ---
+	assert.Equal(t, SeverityWarning, got.sev)
+}
+
+func Test_Error_MarshalJSON_severity(t *testing.T) {
+	t.Run("set", func(t *testing.T) {
+		// --- Given ---
+		e := New("test msg", "ECTest").SetSeverity(SeverityWarning)
+
+		// --- When ---
+		data, err := json.Marshal(e)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		exp := `{"error":"test msg","code":"ECTest","severity":"warning"}`
+		assert.Equal(t, exp, string(data))
+	})
+
+	t.Run("registered", func(t *testing.T) {
+		// --- Given ---
+		resetRegistry(t)
+		Register(CodeInfo{Code: "ECTest", Severity: SeverityCritical})
+		e := New("test msg", "ECTest")
+
+		// --- When ---
+		data, err := json.Marshal(e)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		exp := `{"error":"test msg","code":"ECTest","severity":"critical"}`
+		assert.Equal(t, exp, string(data))
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		// --- Given ---
+		data := []byte(`{"error":"test msg","code":"ECTest","severity":"info"}`)
+
+		// --- When ---
+		var e *Error
+		err := json.Unmarshal(data, &e)
+
+		// --- Then ---
+		assert.NoError(t, err)
+		assert.Equal(t, SeverityInfo, e.sev)
+	})
+}
@@ -0,0 +1,33 @@
+package zrr
+
+import (
+	"log/slog"
+)
+
+// LogValue implements slog.LogValuer interface. The error is logged as a
+// group with the error message, code, severity (when set) and metadata
+// group with keys in the order set with SetMetaOrder. Sensitive values are
+// masked and the limits set with SetLimits are applied.
+func (e *Error) LogValue() slog.Value {
+	msg, size := outMsg(e.Error())
+	attrs := []slog.Attr{
+		slog.String("error", msg),
+		slog.String("code", e.code),
+	}
+	if sev := GetSeverity(e); sev != SeverityDefault {
+		attrs = append(attrs, slog.String("severity", sev.String()))
+	}
+	if len(e.meta) > 0 {
+		fields := outFields(e.metaKeys(), e.meta, size)
+		meta := make([]any, 0, len(fields))
+		for _, f := range fields {
+			meta = append(meta, slog.Any(f.key, f.val))
+		}
+		attrs = append(attrs, slog.Group("meta", meta...))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// LogLevel returns slog level corresponding to the err severity. Errors
+// without severity are logged at slog.LevelError.
+func LogLevel(err error) slog.Level { return GetSeverity(err).Level() }
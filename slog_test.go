@@ -0,0 +1,55 @@
+package zrr
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+)
+
+func Test_Error_LogValue(t *testing.T) {
+	t.Run("with meta", func(t *testing.T) {
+		// --- Given ---
+		buf := &bytes.Buffer{}
+		log := slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{
+			ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+				if a.Key == slog.TimeKey {
+					return slog.Attr{}
+				}
+				return a
+			},
+		}))
+		err := New("em0", "ECode").
+			SetSeverity(SeverityWarning).
+			Str("b", "1").
+			Int("a", 2)
+
+		// --- When ---
+		log.Info("msg", "err", err)
+
+		// --- Then ---
+		exp := `{"level":"INFO","msg":"msg","err":{"error":"em0","code":"ECode",` +
+			`"severity":"warning","meta":{"b":"1","a":2}}}` + "\n"
+		assert.Equal(t, exp, buf.String())
+	})
+
+	t.Run("without meta", func(t *testing.T) {
+		// --- Given ---
+		err := New("em0")
+
+		// --- When ---
+		got := err.LogValue()
+
+		// --- Then ---
+		assert.Equal(t, slog.KindGroup, got.Kind())
+		assert.Equal(t, "[error=em0 code=]", got.String())
+	})
+}
+
+func Test_LogLevel(t *testing.T) {
+	assert.Equal(t, slog.LevelError, LogLevel(New("em0")))
+	assert.Equal(t, slog.LevelError, LogLevel(errors.New("em0")))
+	assert.Equal(t, slog.LevelWarn, LogLevel(New("em0").SetSeverity(SeverityWarning)))
+}
@@ -0,0 +1,121 @@
+package zrr
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// ECTemplate represents invalid template instance error code.
+const ECTemplate = "ECTemplate"
+
+// KeyTemplateErrors is the metadata key for the list of template validation
+// problems found when instantiating a template.
+const KeyTemplateErrors = "template_errors"
+
+// ErrTemplate represents package level error indicating a template instance
+// is missing required metadata keys or has values of a wrong type.
+var ErrTemplate = Imm("invalid template instance", ECTemplate)
+
+// templateStrict when true, Template.With panics on validation failure.
+var templateStrict atomic.Bool
+
+// SetTemplateStrict sets whether Template.With panics with ErrTemplate when
+// the instance is invalid. When not strict the problems are recorded in the
+// instance metadata under KeyTemplateErrors. By default, the validation is
+// not strict, turn it on in tests (for example in TestMain) to catch
+// invalid instances early.
+func SetTemplateStrict(strict bool) { templateStrict.Store(strict) }
+
+// TemplateKey represents a typed metadata key required by a Template.
+type TemplateKey interface {
+	// Name returns metadata key name.
+	Name() string
+
+	// check returns validation problem description or empty string if the
+	// value v is valid for the key.
+	check(v any) string
+}
+
+// Key represents a metadata key with values of type T.
+type Key[T any] string
+
+// Name returns metadata key name.
+func (k Key[T]) Name() string { return string(k) }
+
+// check returns validation problem description or empty string if the value
+// v is of type T.
+func (k Key[T]) check(v any) string {
+	if _, ok := v.(T); ok {
+		return ""
+	}
+	var zero T
+	return fmt.Sprintf("key %q must be %T, got %T", string(k), zero, v)
+}
+
+// Template represents a declaration of an error - its message, code and
+// the metadata keys every instance must have.
+type Template struct {
+	err  *Error        // Immutable sentinel.
+	keys []TemplateKey // Required keys.
+}
+
+// NewTemplate returns new Template with the message, code and required keys.
+func NewTemplate(msg, code string, keys ...TemplateKey) *Template {
+	return &Template{err: Imm(msg, code), keys: keys}
+}
+
+// Err returns immutable sentinel error all template instances wrap. Use it
+// with errors.Is to check if an error is an instance of the template.
+func (t *Template) Err() *Error { return t.err }
+
+// Keys returns keys required by the template.
+func (t *Template) Keys() []TemplateKey { return t.keys }
+
+// With returns new template instance with metadata set from key value
+// pairs. Keys must be instances of TemplateKey or strings. The values of
+// required keys are type-checked no matter how the key was given, values of
+// other keys are not validated.
+//
+// When any of the required keys is missing or has a value of a wrong type
+// With panics with ErrTemplate if SetTemplateStrict is set, otherwise the
+// problems are recorded in the instance metadata under KeyTemplateErrors.
+func (t *Template) With(kvs ...any) *Error {
+	ne := t.err.mutable()
+	var problems []string
+	for i := 0; i < len(kvs); i += 2 {
+		var key string
+		switch k := kvs[i].(type) {
+		case TemplateKey:
+			key = k.Name()
+		case string:
+			key = k
+		default:
+			problems = append(problems, fmt.Sprintf("invalid key %v", k))
+			continue
+		}
+		if i+1 == len(kvs) {
+			problems = append(problems, fmt.Sprintf("key %q has no value", key))
+			continue
+		}
+		ne.set(key, kvs[i+1])
+	}
+
+	for _, key := range t.keys {
+		val, ok := ne.meta[key.Name()]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("missing key %q", key.Name()))
+			continue
+		}
+		if p := key.check(val); p != "" {
+			problems = append(problems, p)
+		}
+	}
+
+	if len(problems) == 0 {
+		return ne
+	}
+	if templateStrict.Load() {
+		panic(ErrTemplate.Str("code", t.err.code).with(KeyTemplateErrors, problems))
+	}
+	return ne.with(KeyTemplateErrors, problems)
+}
@@ -0,0 +1,163 @@
+package zrr
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ctx42/testing/pkg/assert"
+)
+
+// setTemplateStrict sets template validation mode for the duration of
+// the test.
+func setTemplateStrict(t *testing.T, strict bool) {
+	t.Helper()
+	saved := templateStrict.Load()
+	t.Cleanup(func() { templateStrict.Store(saved) })
+	SetTemplateStrict(strict)
+}
+
+func Test_Key(t *testing.T) {
+	// --- Given ---
+	key := Key[int]("user_id")
+
+	// --- Then ---
+	assert.Equal(t, "user_id", key.Name())
+	assert.Equal(t, "", key.check(42))
+	assert.Equal(t, `key "user_id" must be int, got string`, key.check("42"))
+	assert.Equal(t, `key "user_id" must be int, got <nil>`, key.check(nil))
+}
+
+func Test_NewTemplate(t *testing.T) {
+	// --- Given ---
+	userID := Key[int]("user_id")
+
+	// --- When ---
+	tpl := NewTemplate("user not found", "ECUserNotFound", userID)
+
+	// --- Then ---
+	assert.True(t, tpl.Err().imm)
+	assert.Equal(t, "user not found", tpl.Err().Error())
+	assert.Equal(t, "ECUserNotFound", tpl.Err().ErrCode())
+	assert.Equal(t, []TemplateKey{userID}, tpl.Keys())
+}
+
+func Test_Template_With(t *testing.T) {
+	userID := Key[int]("user_id")
+	tenant := Key[string]("tenant")
+
+	t.Run("valid", func(t *testing.T) {
+		// --- Given ---
+		setTemplateStrict(t, true)
+		tpl := NewTemplate("user not found", "ECUserNotFound", userID, tenant)
+
+		// --- When ---
+		err := tpl.With(userID, 42, tenant, "acme", "extra", true)
+
+		// --- Then ---
+		assert.True(t, errors.Is(err, tpl.Err()))
+		assert.False(t, err.imm)
+		assert.Equal(t, "user not found", err.Error())
+		assert.Equal(t, "ECUserNotFound", err.ErrCode())
+		assert.Equal(t, []string{"user_id", "tenant", "extra"}, err.keys)
+		have, _ := GetInt(err, "user_id")
+		assert.Equal(t, 42, have)
+		assert.False(t, HasKey(err, KeyTemplateErrors))
+		assert.Equal(t, map[string]any{}, tpl.Err().meta)
+	})
+
+	t.Run("string key for required key", func(t *testing.T) {
+		// --- Given ---
+		setTemplateStrict(t, true)
+		tpl := NewTemplate("user not found", "ECUserNotFound", userID)
+
+		// --- When ---
+		err := tpl.With("user_id", 42)
+
+		// --- Then ---
+		assert.False(t, HasKey(err, KeyTemplateErrors))
+	})
+
+	t.Run("string key for required key is validated", func(t *testing.T) {
+		// --- Given ---
+		setTemplateStrict(t, false)
+		tpl := NewTemplate("user not found", "ECUserNotFound", userID)
+
+		// --- When ---
+		err := tpl.With("user_id", "x")
+
+		// --- Then ---
+		have, _ := GetStrs(err, KeyTemplateErrors)
+		assert.Equal(t, []string{`key "user_id" must be int, got string`}, have)
+	})
+
+	t.Run("strict panics", func(t *testing.T) {
+		// --- Given ---
+		setTemplateStrict(t, true)
+		tpl := NewTemplate("user not found", "ECUserNotFound", userID)
+
+		// --- When ---
+		var val any
+		func() {
+			defer func() { val = recover() }()
+			tpl.With(userID, "42")
+		}()
+
+		// --- Then ---
+		err, ok := val.(*Error)
+		assert.True(t, ok)
+		assert.True(t, errors.Is(err, ErrTemplate))
+		code, _ := GetStr(err, "code")
+		assert.Equal(t, "ECUserNotFound", code)
+		problems, _ := GetStrs(err, KeyTemplateErrors)
+		want := []string{`key "user_id" must be int, got string`}
+		assert.Equal(t, want, problems)
+	})
+
+	t.Run("not strict flags metadata", func(t *testing.T) {
+		// --- Given ---
+		setTemplateStrict(t, false)
+		tpl := NewTemplate("user not found", "ECUserNotFound", userID, tenant)
+
+		// --- When ---
+		err := tpl.With(userID, int64(42), 7, "x", "odd")
+
+		// --- Then ---
+		assert.True(t, errors.Is(err, tpl.Err()))
+		have, _ := GetStrs(err, KeyTemplateErrors)
+		want := []string{
+			"invalid key 7",
+			`key "odd" has no value`,
+			`key "user_id" must be int, got int64`,
+			`missing key "tenant"`,
+		}
+		assert.Equal(t, want, have)
+	})
+
+	t.Run("no required keys", func(t *testing.T) {
+		// --- Given ---
+		setTemplateStrict(t, true)
+		tpl := NewTemplate("user not found", "ECUserNotFound")
+
+		// --- When ---
+		err := tpl.With()
+
+		// --- Then ---
+		assert.NotSame(t, tpl.Err(), err)
+		assert.True(t, errors.Is(err, tpl.Err()))
+	})
+}
+
+func Test_SetTemplateStrict(t *testing.T) {
+	// --- Given ---
+	setTemplateStrict(t, true)
+
+	// --- When ---
+	SetTemplateStrict(false)
+
+	// --- Then ---
+	assert.False(t, templateStrict.Load())
+}
+
+func Test_templateStrict_default(t *testing.T) {
+	assert.False(t, templateStrict.Load())
+}
@@ -49,7 +49,7 @@ func AssertCode(t *testing.T, err error, exp string, _ ...any) {
 }
 
 // AssertEqual asserts err and got are instance of zrr.Error and their error
-// messages (with key value pairs) are equal.
+// messages, codes and key value pairs are equal (see zrr.Diff).
 func AssertEqual(t *testing.T, exp, got error, _ ...any) {
 	t.Helper()
 
@@ -58,7 +58,9 @@ func AssertEqual(t *testing.T, exp, got error, _ ...any) {
 	var EE, EG *zrr.Error
 	assert.ErrorAs(t, &EE, exp)
 	assert.ErrorAs(t, &EG, got)
-	assert.Equal(t, EE.Error(), EG.Error())
+	for _, diff := range zrr.Diff(EE, EG) {
+		t.Errorf("expected errors to be equal: %s", diff)
+	}
 }
 
 // AssertNoKey asserts err is instance of zrr.Error and has no key set.
@@ -128,3 +130,36 @@ func AssertBool(t *testing.T, err error, key string, exp bool, _ ...any) {
 	assert.True(t, ok)
 	assert.Equal(t, exp, got)
 }
+
+// AssertDuration asserts err is instance of zrr.Error and has key with
+// value exp.
+func AssertDuration(t *testing.T, err error, key string, exp time.Duration, _ ...any) {
+	t.Helper()
+
+	assert.NotNil(t, err)
+	got, ok := zrr.GetDuration(err, key)
+	assert.True(t, ok)
+	assert.Equal(t, exp, got)
+}
+
+// AssertStrs asserts err is instance of zrr.Error and has key with a list of
+// strings equal to exp.
+func AssertStrs(t *testing.T, err error, key string, exp []string, _ ...any) {
+	t.Helper()
+
+	assert.NotNil(t, err)
+	got, ok := zrr.GetStrs(err, key)
+	assert.True(t, ok)
+	assert.Equal(t, exp, got)
+}
+
+// AssertInts asserts err is instance of zrr.Error and has key with a list of
+// integers equal to exp.
+func AssertInts(t *testing.T, err error, key string, exp []int, _ ...any) {
+	t.Helper()
+
+	assert.NotNil(t, err)
+	got, ok := zrr.GetInts(err, key)
+	assert.True(t, ok)
+	assert.Equal(t, exp, got)
+}